@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -11,6 +12,7 @@ import (
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/handlers"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/middleware"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/routes"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/scheduler"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
@@ -30,6 +32,19 @@ func main() {
 		log.Fatal("DB_PASSWORD environment variable is required")
 	}
 
+	// Webhook verification; refuses to start without a secret so forged events can't get in
+	revenueCatWebhook, err := middleware.VerifyWebhook(middleware.WebhookConfig{
+		Name:            "RevenueCat",
+		AuthHeader:      "Authorization",
+		AuthSecrets:     cfg.RevenueCatWebhookAuth,
+		SignatureHeader: cfg.RevenueCatWebhookSigHeader,
+		SigningSecrets:  cfg.RevenueCatWebhookHMAC,
+		AllowedIPs:      cfg.RevenueCatWebhookAllowedIPs,
+	})
+	if err != nil {
+		log.Fatalf("Invalid webhook configuration (set REVENUECAT_WEBHOOK_AUTH or REVENUECAT_WEBHOOK_HMAC_SECRETS): %v", err)
+	}
+
 	// Database
 	if err := database.Connect(cfg); err != nil {
 		log.Fatalf("Database connection failed: %v", err)
@@ -40,17 +55,51 @@ func main() {
 
 	// Services
 	authService := services.NewAuthService(database.DB, cfg)
-	subscriptionService := services.NewSubscriptionService(database.DB)
+	roleService := services.NewRoleService(database.DB)
+	entitlementService := services.NewEntitlementService(database.DB, cfg.PremiumEntitlement)
+	planService := services.NewPlanService(entitlementService, cfg.PlansFile)
+	freezeService := services.NewFreezeService(database.DB, cfg.FreezeProductIDs, planService)
+	subscriptionService := services.NewSubscriptionService(database.DB, freezeService, entitlementService, services.NewRevenueCatClient(cfg.RevenueCatAPIURL, cfg.RevenueCatAPIKey))
 	moderationService := services.NewModerationService(database.DB)
-	snapService := services.NewSnapService(database.DB)
+	notifiers := []services.Notifier{services.LogNotifier{}, services.NewExpoPushNotifier()}
+	if cfg.SMTPHost != "" {
+		notifiers = append(notifiers, services.NewEmailNotifier(cfg))
+	}
+	notificationService := services.NewNotificationService(database.DB, notifiers...)
+	achievementService := services.NewAchievementService(database.DB, notificationService, cfg.AchievementsFile)
+	snapService := services.NewSnapService(database.DB, notificationService, achievementService, planService)
+	reminderService := services.NewReminderService(database.DB, notificationService, cfg.StreakReminderCutoff)
+	windowService := services.NewWindowService(database.DB, notificationService)
+	promptService := services.NewPromptService(database.DB)
+	cardService := services.NewCardService(database.DB, "./cards")
+	statsService := services.NewStatsService(database.DB)
+	recapService := services.NewRecapService(database.DB, notificationService, "./recaps")
+	timelapseService := services.NewTimelapseService(database.DB, "./timelapses")
+	analyticsService := services.NewAnalyticsService(database.DB)
+	auditService := services.NewAuditService(database.DB)
+
+	if err := promptService.SeedDefaults(); err != nil {
+		log.Printf("Warning: Could not seed default prompts: %v", err)
+	}
 
 	// Handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	healthHandler := handlers.NewHealthHandler()
-	webhookHandler := handlers.NewWebhookHandler(subscriptionService, cfg)
+	webhookHandler := handlers.NewWebhookHandler(subscriptionService, auditService)
 	moderationHandler := handlers.NewModerationHandler(moderationService)
-	snapHandler := handlers.NewSnapHandler(snapService)
+	snapHandler := handlers.NewSnapHandler(snapService, windowService, freezeService)
 	legalHandler := handlers.NewLegalHandler()
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	promptHandler := handlers.NewPromptHandler(promptService)
+	achievementHandler := handlers.NewAchievementHandler(achievementService)
+	cardHandler := handlers.NewCardHandler(cardService)
+	statsHandler := handlers.NewStatsHandler(statsService)
+	recapHandler := handlers.NewRecapHandler(recapService)
+	timelapseHandler := handlers.NewTimelapseHandler(timelapseService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(entitlementService, planService, subscriptionService, auditService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService, subscriptionService, auditService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 
 	// Create uploads directory for snap images
 	if err := os.MkdirAll("./uploads/snaps", 0755); err != nil {
@@ -61,6 +110,13 @@ func main() {
 	app := fiber.New(fiber.Config{
 		BodyLimit:    12 * 1024 * 1024, // 12MB (10MB image + form overhead)
 		ErrorHandler: customErrorHandler,
+
+		// c.IP() only believes ProxyHeader on connections from the configured proxies, and falls
+		// back to the connection's address when the header doesn't hold a valid IP
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableIPValidation:      true,
 	})
 
 	// Global middleware
@@ -83,7 +139,19 @@ func main() {
 	app.Use("/api/auth", authLimiter)
 
 	// Routes
-	routes.Setup(app, cfg, authHandler, healthHandler, webhookHandler, moderationHandler, snapHandler, legalHandler)
+	routes.Setup(app, cfg, authHandler, healthHandler, webhookHandler, moderationHandler, snapHandler, legalHandler, notificationHandler, promptHandler, achievementHandler, cardHandler, statsHandler, recapHandler, timelapseHandler, subscriptionHandler, analyticsHandler, roleHandler, auditHandler, entitlementService, roleService, revenueCatWebhook)
+
+	// Background jobs (lease-guarded, safe to run on every replica)
+	jobs := scheduler.New(database.DB)
+	jobs.Every("streak_reminders", cfg.StreakReminderInterval, reminderService.SendStreakReminders)
+	jobs.Every("snap_window", cfg.SnapWindowInterval, windowService.NotifyOpenWindows)
+	jobs.Every("streak_rollover", cfg.StreakRolloverInterval, snapService.RolloverStreaks)
+	jobs.Every("recaps", cfg.RecapInterval, recapService.GenerateRecaps)
+	jobs.Every("timelapses", cfg.TimelapseInterval, timelapseService.ProcessJobs)
+	jobs.Every("subscription_reconcile", cfg.ReconcileInterval, subscriptionService.ReconcileSubscriptions)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs.Start(jobsCtx)
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -99,6 +167,8 @@ func main() {
 
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
+	jobs.Wait()
 	if err := app.Shutdown(); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"time"
 )
 
@@ -17,10 +18,41 @@ type Config struct {
 	JWTAccessExpiry  time.Duration
 	JWTRefreshExpiry time.Duration
 
-	RevenueCatWebhookAuth string
+	FreezeProductIDs   string // "product_id[:quantity],..." for consumable streak freezes
+	PremiumEntitlement string // entitlement granted by subscriptions that don't list their own
+	RevenueCatAPIKey   string // secret REST API key; reconciliation is disabled without it
+	RevenueCatAPIURL   string
+
+	RevenueCatWebhookAuth       []string // accepted Authorization header values; several while rotating
+	RevenueCatWebhookHMAC       []string // optional HMAC-SHA256 signing secrets
+	RevenueCatWebhookSigHeader  string   // header carrying the HMAC signature
+	RevenueCatWebhookAllowedIPs []string // optional source IPs or CIDRs, matched against the client IP (see TrustedProxies)
+
+	StreakReminderCutoff   string // local time of day, "HH:MM"
+	StreakReminderInterval time.Duration
+	SnapWindowInterval     time.Duration
+	StreakRolloverInterval time.Duration
+	RecapInterval          time.Duration
+	TimelapseInterval      time.Duration
+	ReconcileInterval      time.Duration
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	SMTPFrom     string
+
+	AchievementsFile string // optional JSON list of achievement definitions
+	PlansFile        string // optional JSON plan-limits table
 
 	Port        string
 	CORSOrigins string
+
+	// The client IP is read from ProxyHeader only on connections from TrustedProxies, otherwise
+	// the connection's own address is used. The proxy must overwrite that header rather than
+	// append to it, so X-Forwarded-For is not safe here.
+	TrustedProxies []string // reverse proxy / load balancer IPs or CIDRs
+	ProxyHeader    string   // header the proxies set to the client IP
 }
 
 func Load() *Config {
@@ -36,10 +68,38 @@ func Load() *Config {
 		JWTAccessExpiry:  parseDuration(getEnv("JWT_ACCESS_EXPIRY", "15m")),
 		JWTRefreshExpiry: parseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h")),
 
-		RevenueCatWebhookAuth: getEnv("REVENUECAT_WEBHOOK_AUTH", ""),
+		FreezeProductIDs:   getEnv("REVENUECAT_FREEZE_PRODUCTS", "streak_freeze_1:1,streak_freeze_3:3"),
+		PremiumEntitlement: getEnv("REVENUECAT_PREMIUM_ENTITLEMENT", "premium"),
+		RevenueCatAPIKey:   getEnv("REVENUECAT_API_KEY", ""),
+		RevenueCatAPIURL:   getEnv("REVENUECAT_API_URL", "https://api.revenuecat.com"),
+
+		RevenueCatWebhookAuth:       getEnvList("REVENUECAT_WEBHOOK_AUTH"),
+		RevenueCatWebhookHMAC:       getEnvList("REVENUECAT_WEBHOOK_HMAC_SECRETS"),
+		RevenueCatWebhookSigHeader:  getEnv("REVENUECAT_WEBHOOK_SIGNATURE_HEADER", "X-Webhook-Signature"),
+		RevenueCatWebhookAllowedIPs: getEnvList("REVENUECAT_WEBHOOK_ALLOWED_IPS"),
+
+		StreakReminderCutoff:   getEnv("STREAK_REMINDER_CUTOFF", "20:00"),
+		StreakReminderInterval: parseDuration(getEnv("STREAK_REMINDER_INTERVAL", "15m")),
+		SnapWindowInterval:     parseDuration(getEnv("SNAP_WINDOW_INTERVAL", "5m")),
+		StreakRolloverInterval: parseDuration(getEnv("STREAK_ROLLOVER_INTERVAL", "1h")),
+		RecapInterval:          parseDuration(getEnv("RECAP_INTERVAL", "1h")),
+		TimelapseInterval:      parseDuration(getEnv("TIMELAPSE_INTERVAL", "10s")),
+		ReconcileInterval:      parseDuration(getEnv("SUBSCRIPTION_RECONCILE_INTERVAL", "6h")),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUser:     getEnv("SMTP_USER", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "StreakSnap <no-reply@streaksnap.app>"),
+
+		AchievementsFile: getEnv("ACHIEVEMENTS_FILE", ""),
+		PlansFile:        getEnv("PLANS_FILE", ""),
 
 		Port:        getEnv("PORT", "8080"),
 		CORSOrigins: getEnv("CORS_ORIGINS", "*"),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+		ProxyHeader:    getEnv("PROXY_HEADER", "X-Real-IP"),
 	}
 }
 
@@ -60,6 +120,17 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvList splits a comma-separated variable, dropping empty entries.
+func getEnvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
@@ -34,6 +34,27 @@ func Connect(cfg *config.Config) error {
 	return nil
 }
 
+// columnChanges relaxes constraints AutoMigrate leaves in place on existing tables and repairs
+// the values they affect.
+var columnChanges = []string{
+	// Subscriptions bought under an unknown RevenueCat ID are stored without a user
+	`ALTER TABLE subscriptions ALTER COLUMN user_id DROP NOT NULL`,
+	// Lifetime purchases were once stored with a 1970 period end; they have none
+	`UPDATE subscriptions SET current_period_end = NULL WHERE current_period_end < '1970-01-02'`,
+}
+
+// auditLogGuard makes audit_logs append-only. Statements run one at a time.
+var auditLogGuard = []string{
+	`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_logs is append-only';
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+	`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+		FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+}
+
 func Migrate() error {
 	err := DB.AutoMigrate(
 		&models.User{},
@@ -43,11 +64,40 @@ func Migrate() error {
 		&models.Block{},
 		&models.Snap{},
 		&models.SnapStreak{},
+		&models.Notification{},
+		&models.NotificationReceipt{},
+		&models.JobLease{},
+		&models.Prompt{},
+		&models.FreezeTransaction{},
+		&models.FrozenDay{},
+		&models.StreakEpoch{},
+		&models.UserAchievement{},
+		&models.MilestoneCard{},
+		&models.Recap{},
+		&models.TimelapseJob{},
+		&models.WebhookEvent{},
+		&models.RevenueCatAlias{},
+		&models.SubscriptionCorrection{},
+		&models.SubscriptionTransaction{},
+		&models.AuditLog{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	for _, stmt := range columnChanges {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to migrate columns: %w", err)
+		}
+	}
+
+	// The audit log is append-only, even for code or operators going around AuditService
+	for _, stmt := range auditLogGuard {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to protect audit log: %w", err)
+		}
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
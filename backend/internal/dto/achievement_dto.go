@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type AchievementResponse struct {
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Icon        string     `json:"icon"`
+	Threshold   int        `json:"threshold"`
+	Earned      bool       `json:"earned"`
+	EarnedAt    *time.Time `json:"earned_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+// RevenueBreakdown is revenue (USD, net of refunds) for one country or product.
+type RevenueBreakdown struct {
+	Key          string  `json:"key"`
+	Revenue      float64 `json:"revenue"`
+	Transactions int64   `json:"transactions"`
+}
+
+// RevenueAnalyticsResponse reports subscription revenue over [From, To] (UTC days, inclusive).
+// Point-in-time figures (MRR, active counts) are as of the end of To.
+type RevenueAnalyticsResponse struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Environment string `json:"environment"`
+	Currency    string `json:"currency"`
+
+	Revenue      float64 `json:"revenue"` // gross, before refunds
+	Refunds      float64 `json:"refunds"`
+	NetRevenue   float64 `json:"net_revenue"`
+	Transactions int64   `json:"transactions"`
+
+	MRR               float64 `json:"mrr"`
+	ActiveSubscribers int64   `json:"active_subscribers"` // paying, excluding trials
+	ActiveTrials      int64   `json:"active_trials"`
+
+	TrialsStarted       int64   `json:"trials_started"`
+	TrialsConverted     int64   `json:"trials_converted"`
+	TrialConversionRate float64 `json:"trial_conversion_rate"`
+
+	SubscribersAtStart int64   `json:"subscribers_at_start"`
+	ChurnedSubscribers int64   `json:"churned_subscribers"`
+	ChurnRate          float64 `json:"churn_rate"`
+
+	ByCountry []RevenueBreakdown `json:"by_country"`
+	ByProduct []RevenueBreakdown `json:"by_product"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogFilter narrows GET /admin/audit-logs; zero fields are ignored.
+type AuditLogFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time // inclusive
+	To         *time.Time // exclusive
+}
+
+type AuditLogResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty"`
+	ActorRole  string          `json:"actor_role,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -46,5 +46,18 @@ type HealthResponse struct {
 type ProfileResponse struct {
 	ID        string `json:"id"`
 	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Timezone  string `json:"timezone"`
 	CreatedAt string `json:"created_at"`
 }
+
+// SetRoleRequest changes a user's role (user, moderator or admin)
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// UpdateProfileRequest updates optional profile settings; nil fields are left unchanged
+type UpdateProfileRequest struct {
+	Timezone  *string `json:"timezone"`   // IANA name, e.g. "Europe/Istanbul"
+	PushToken *string `json:"push_token"` // Expo push token, empty string to unregister
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// MilestoneCardRequest selects the milestone to share; 0 or omitted means the current streak.
+type MilestoneCardRequest struct {
+	Milestone int `json:"milestone"`
+}
+
+type MilestoneCardResponse struct {
+	ID        string    `json:"id"`
+	Milestone int       `json:"milestone"`
+	ShareURL  string    `json:"share_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// PromptRequest creates or updates a prompt; nil fields are left unchanged on update.
+type PromptRequest struct {
+	Text          *string `json:"text"`
+	Emoji         *string `json:"emoji"`
+	Category      *string `json:"category"`
+	Locale        *string `json:"locale"`
+	ScheduledDate *string `json:"scheduled_date"` // YYYY-MM-DD, empty string to unschedule
+	InRotation    *bool   `json:"in_rotation"`
+}
+
+type PromptResponse struct {
+	ID       string `json:"id"`
+	Text     string `json:"text"`
+	Emoji    string `json:"emoji"`
+	Category string `json:"category"`
+	Locale   string `json:"locale"`
+	Date     string `json:"date"`
+}
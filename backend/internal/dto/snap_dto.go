@@ -16,6 +16,8 @@ type SnapResponse struct {
 	Filter    string    `json:"filter"`
 	SnapDate  time.Time `json:"snap_date"`
 	LikeCount int       `json:"like_count"`
+	WindowStatus string `json:"window_status"` // "early", "on_time" or "late"
+	PromptID  *string   `json:"prompt_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -42,3 +44,150 @@ type SnapsListResponse struct {
 	Page  int            `json:"page"`
 	Limit int            `json:"limit"`
 }
+
+type SnapWindowResponse struct {
+	Date             string    `json:"date"`
+	Timezone         string    `json:"timezone"`
+	OpensAt          time.Time `json:"opens_at"`
+	ClosesAt         time.Time `json:"closes_at"`
+	IsOpen           bool      `json:"is_open"`
+	RemainingSeconds int64     `json:"remaining_seconds"`
+}
+
+// StreakSummary is the comparable part of a streak record used in rebuild diffs.
+type StreakSummary struct {
+	CurrentStreak  int    `json:"current_streak"`
+	LongestStreak  int    `json:"longest_streak"`
+	TotalSnaps     int    `json:"total_snaps"`
+	FreezesUsed    int    `json:"freezes_used"`
+	PastStreaks    int    `json:"past_streaks"`
+	PastStreakDays int    `json:"past_streak_days"`
+	LastSnapDate   string `json:"last_snap_date,omitempty"`
+}
+
+type StreakRebuildResult struct {
+	UserID  string        `json:"user_id"`
+	Before  StreakSummary `json:"before"`
+	After   StreakSummary `json:"after"`
+	Changed bool          `json:"changed"`
+	Applied bool          `json:"applied"`
+}
+
+type StreakRebuildReport struct {
+	DryRun  bool                  `json:"dry_run"`
+	Checked int                   `json:"checked"`
+	Changed []StreakRebuildResult `json:"changed"`
+}
+
+type StreakEpochResponse struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Length    int    `json:"length"`
+}
+
+type StreakHistoryResponse struct {
+	CurrentStreak    int                   `json:"current_streak"`
+	CurrentStartDate string                `json:"current_start_date,omitempty"`
+	LongestStreak    int                   `json:"longest_streak"`
+	PastStreaks      []StreakEpochResponse `json:"past_streaks"`
+}
+
+type CalendarDay struct {
+	Date         string `json:"date"`
+	Count        int    `json:"count"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	Status       string `json:"status"` // active, frozen, missed, pending, none, locked
+}
+
+// CalendarResponse covers every local day from From to To. Dates and FrozenDates list the
+// snapped and frozen days for older clients.
+type CalendarResponse struct {
+	From         string        `json:"from"`
+	To           string        `json:"to"`
+	Timezone     string        `json:"timezone"`
+	Calendar     []CalendarDay `json:"calendar"`
+	Dates        []string      `json:"dates"`
+	FrozenDates  []string      `json:"frozen_dates"`
+	HistoryStart string        `json:"history_start,omitempty"` // earliest day the plan shows; older days are locked
+}
+
+type WeekdayCount struct {
+	Weekday string `json:"weekday"`
+	Count   int    `json:"count"`
+}
+
+type MonthCompletion struct {
+	Month    string  `json:"month"`     // YYYY-MM
+	SnapDays int     `json:"snap_days"` // distinct days with a snap
+	Days     int     `json:"days"`      // days the user could have snapped (since their first snap, up to today)
+	Rate     float64 `json:"rate"`      // SnapDays / Days
+}
+
+// SnapGap is the longest run of days without a snap; From and To are the snapped days around it.
+type SnapGap struct {
+	Days int    `json:"days"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type StatsResponse struct {
+	Timezone          string            `json:"timezone"`
+	TotalSnaps        int               `json:"total_snaps"`
+	SnapsPerWeekday   []WeekdayCount    `json:"snaps_per_weekday"` // Monday first
+	AverageHour       *float64          `json:"average_hour"`      // local hour of day, e.g. 18.5 = 18:30
+	MostUsedFilter    string            `json:"most_used_filter,omitempty"`
+	FilterCounts      map[string]int    `json:"filter_counts"`
+	MonthlyCompletion []MonthCompletion `json:"monthly_completion"`
+	LongestGap        *SnapGap          `json:"longest_gap"`
+	GeneratedAt       time.Time         `json:"generated_at"`
+}
+
+type RecapSnap struct {
+	ID        string    `json:"id"`
+	ImageURL  string    `json:"image_url"`
+	LikeCount int       `json:"like_count"`
+	SnapDate  time.Time `json:"snap_date"`
+}
+
+type RecapSummary struct {
+	SnapCount       int            `json:"snap_count"`
+	ActiveDays      int            `json:"active_days"`
+	Days            int            `json:"days"`
+	LongestStreak   int            `json:"longest_streak"` // consecutive snapped days within the period
+	FrozenDays      int            `json:"frozen_days"`
+	TotalLikes      int            `json:"total_likes"`
+	PromptsAnswered int            `json:"prompts_answered"`
+	TopFilter       string         `json:"top_filter,omitempty"`
+	FilterCounts    map[string]int `json:"filter_counts"`
+	TopLikedSnaps   []RecapSnap    `json:"top_liked_snaps"`
+}
+
+type RecapResponse struct {
+	ID        string       `json:"id"`
+	Period    string       `json:"period"`
+	PeriodKey string       `json:"period_key"`
+	Title     string       `json:"title"`
+	ImageURL  string       `json:"image_url,omitempty"`
+	Summary   RecapSummary `json:"summary"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type TimelapseRequest struct {
+	From   string `json:"from"`   // YYYY-MM-DD
+	To     string `json:"to"`     // YYYY-MM-DD
+	Format string `json:"format"` // "gif" or "mp4" (no WebP); empty picks the best available
+}
+
+type TimelapseResponse struct {
+	ID          string     `json:"id"`
+	Format      string     `json:"format"`
+	From        string     `json:"from"`
+	To          string     `json:"to"`
+	Status      string     `json:"status"`
+	Progress    int        `json:"progress"`
+	FrameCount  int        `json:"frame_count"`
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+import "time"
+
+type EntitlementResponse struct {
+	ID        string     `json:"id"`
+	ExpiresAt *time.Time `json:"expires_at"` // null for lifetime access
+}
+
+type SubscriptionResponse struct {
+	ProductID            string     `json:"product_id"`
+	PendingProductID     string     `json:"pending_product_id,omitempty"`
+	Status               string     `json:"status"`
+	WillRenew            bool       `json:"will_renew"`
+	CurrentPeriodEnd     *time.Time `json:"current_period_end"` // null for lifetime purchases
+	GracePeriodExpiresAt *time.Time `json:"grace_period_expires_at,omitempty"`
+	AutoResumeAt         *time.Time `json:"auto_resume_at,omitempty"`
+	Entitlements         []string   `json:"entitlements"`
+}
+
+type SubscriptionStatusResponse struct {
+	IsPremium    bool                  `json:"is_premium"`
+	Entitlements []EntitlementResponse `json:"entitlements"`
+	Subscription *SubscriptionResponse `json:"subscription"` // latest subscription, if any
+	Plan         *PlanResponse         `json:"plan"`
+}
+
+// PlanResponse describes what the user's plan allows.
+type PlanResponse struct {
+	Name                string   `json:"name"`
+	MaxFreezes          int      `json:"max_freezes"`
+	MonthlyFreezeClaims int      `json:"monthly_freeze_claims"`
+	CalendarHistoryDays int      `json:"calendar_history_days"` // 0 = unlimited
+	OriginalResolution  bool     `json:"original_resolution"`
+	MaxImageDimension   int      `json:"max_image_dimension,omitempty"`
+	Filters             []string `json:"filters"`
+}
+
+// IdentifyRequest registers the RevenueCat app user ID the client uses for the signed-in user.
+type IdentifyRequest struct {
+	AppUserID string   `json:"app_user_id"`
+	Aliases   []string `json:"aliases"`
+}
+
+type ReconcileOrphansResponse struct {
+	Checked         int `json:"checked"`
+	Linked          int `json:"linked"`
+	Remaining       int `json:"remaining"`
+	PurchasesLinked int `json:"purchases_linked"`
+}
+
+// CorrectionResponse is one subscription field fixed by reconciliation.
+type CorrectionResponse struct {
+	SubscriptionID string `json:"subscription_id"`
+	RevenueCatID   string `json:"revenuecat_id"`
+	Field          string `json:"field"`
+	OldValue       string `json:"old_value"`
+	NewValue       string `json:"new_value"`
+}
+
+// ReconcileReport summarizes a reconciliation run against the RevenueCat API.
+type ReconcileReport struct {
+	Checked     int                  `json:"checked"`
+	Corrected   int                  `json:"corrected"`
+	Failed      int                  `json:"failed"`
+	Corrections []CorrectionResponse `json:"corrections"`
+}
@@ -8,6 +8,7 @@ type RevenueCatWebhook struct {
 type RevenueCatEvent struct {
 	Type                     string   `json:"type"`
 	ID                       string   `json:"id"`
+	EventTimestampMs         int64    `json:"event_timestamp_ms"`
 	AppUserID                string   `json:"app_user_id"`
 	ProductID                string   `json:"product_id"`
 	EntitlementIDs           []string `json:"entitlement_ids"`
@@ -24,4 +25,12 @@ type RevenueCatEvent struct {
 	Currency                 string   `json:"currency"`
 	Price                    float64  `json:"price"`
 	PriceInPurchasedCurrency float64  `json:"price_in_purchased_currency"`
+	NewProductID             string   `json:"new_product_id"`                // PRODUCT_CHANGE
+	GracePeriodExpiresAtMs   *int64   `json:"grace_period_expiration_at_ms"` // BILLING_ISSUE
+	AutoResumeAtMs           *int64   `json:"auto_resume_at_ms"`             // SUBSCRIPTION_PAUSED
+	CancelReason             string   `json:"cancel_reason"`
+	ExpirationReason         string   `json:"expiration_reason"`
+	TransferredFrom          []string `json:"transferred_from"` // TRANSFER
+	TransferredTo            []string `json:"transferred_to"`
+	Aliases                  []string `json:"aliases"` // SUBSCRIBER_ALIAS
 }
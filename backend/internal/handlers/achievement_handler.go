@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AchievementHandler struct {
+	achievementService *services.AchievementService
+}
+
+func NewAchievementHandler(achievementService *services.AchievementService) *AchievementHandler {
+	return &AchievementHandler{achievementService: achievementService}
+}
+
+// ListAchievements handles GET /achievements — returns every achievement with the user's earned status.
+func (h *AchievementHandler) ListAchievements(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	achievements, err := h.achievementService.List(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch achievements",
+		})
+	}
+
+	earned := 0
+	for _, a := range achievements {
+		if a.Earned {
+			earned++
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"achievements": achievements,
+		"earned":       earned,
+		"total":        len(achievements),
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AnalyticsHandler struct {
+	analyticsService    *services.AnalyticsService
+	subscriptionService *services.SubscriptionService
+	auditService        *services.AuditService
+}
+
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService, subscriptionService *services.SubscriptionService, auditService *services.AuditService) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsService: analyticsService, subscriptionService: subscriptionService, auditService: auditService}
+}
+
+// GetRevenue handles GET /admin/analytics/revenue?from=YYYY-MM-DD&to=YYYY-MM-DD&environment=SANDBOX
+// — MRR, active subscribers, trial conversion, churn and revenue by country and product.
+func (h *AnalyticsHandler) GetRevenue(c *fiber.Ctx) error {
+	report, err := h.analyticsService.RevenueAnalytics(c.Query("from"), c.Query("to"), c.Query("environment"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Invalid date range; use from/to as YYYY-MM-DD with from <= to",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to compute revenue analytics",
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// BackfillTransactions records transactions for webhook events logged before revenue data was kept.
+func (h *AnalyticsHandler) BackfillTransactions(c *fiber.Ctx) error {
+	added, err := h.subscriptionService.BackfillTransactions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to backfill transactions",
+		})
+	}
+
+	if err := recordAudit(c, h.auditService, models.AuditTransactionsBackfilled, "subscription_transaction", "", nil, fiber.Map{"added": added}); err != nil {
+		return auditFailed(c, err)
+	}
+
+	return c.JSON(fiber.Map{"added": added})
+}
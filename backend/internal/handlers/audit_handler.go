@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// newAuditEntry describes the current request's action for the audit log, with the caller as
+// actor and the request ID and IP for tracing. Services write it in the same transaction as the
+// change it describes.
+func newAuditEntry(c *fiber.Ctx, action, targetType, targetID string) *services.AuditEntry {
+	entry := &services.AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         c.IP(),
+	}
+	if userID, err := extractUserID(c); err == nil {
+		entry.ActorID = &userID
+	}
+	if role, ok := c.Locals("role").(string); ok {
+		entry.ActorRole = role
+	}
+	if requestID, ok := c.Locals("requestid").(string); ok {
+		entry.RequestID = requestID
+	}
+	return entry
+}
+
+// recordAudit appends the current request's action to the audit log after it has run. It is for
+// actions that span several transactions and so can't carry the entry themselves; the caller
+// must fail the request with auditFailed when it returns an error.
+func recordAudit(c *fiber.Ctx, audit *services.AuditService, action, targetType, targetID string, before, after interface{}) error {
+	entry := newAuditEntry(c, action, targetType, targetID)
+	entry.Before, entry.After = before, after
+	return audit.Record(*entry)
+}
+
+// auditFailed answers a request whose action was applied but could not be written to the audit
+// log, so the failure is seen rather than leaving a silent gap in the log.
+func auditFailed(c *fiber.Ctx, err error) error {
+	log.Printf("error: %s %s was applied but not audited: %v", c.Method(), c.Path(), err)
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "The change was applied but could not be recorded in the audit log",
+	})
+}
+
+// pageParams reads ?limit= and ?offset= for a paginated list. The limit falls back to def when
+// missing or malformed and is clamped to 1..max, so no value can turn into "no limit"; ok is
+// false for a malformed or negative offset.
+func pageParams(c *fiber.Ctx, def, max int) (limit, offset int, ok bool) {
+	limit = c.QueryInt("limit", def)
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > max {
+		limit = max
+	}
+	offset, err := strconv.Atoi(c.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
+
+// ListAuditLogs handles GET /admin/audit-logs — filterable by actor_id, action, target_type,
+// target_id, request_id and from/to (YYYY-MM-DD, inclusive).
+func (h *AuditHandler) ListAuditLogs(c *fiber.Ctx) error {
+	limit, offset, ok := pageParams(c, 20, 100)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid offset",
+		})
+	}
+
+	filter := dto.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		RequestID:  c.Query("request_id"),
+	}
+	if raw := c.Query("actor_id"); raw != "" {
+		actorID, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Invalid actor ID",
+			})
+		}
+		filter.ActorID = &actorID
+	}
+	from, to, err := services.ParseAuditRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid date range; use from/to as YYYY-MM-DD with from <= to",
+		})
+	}
+	filter.From, filter.To = from, to
+
+	entries, total, err := h.auditService.List(filter, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch audit logs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"entries": entries,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
@@ -129,7 +130,8 @@ func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.authService.DeleteAccount(userID, req.Password); err != nil {
+	audit := newAuditEntry(c, models.AuditAccountDeleted, "user", userID.String())
+	if err := h.authService.DeleteAccount(userID, req.Password, audit); err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
 			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
 				Error: true, Message: "Incorrect password",
@@ -169,6 +171,42 @@ func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateProfile handles PUT /auth/profile — updates time zone and push token
+func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	profile, err := h.authService.UpdateProfile(userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to update profile",
+		})
+	}
+
+	return c.JSON(fiber.Map{"data": profile})
+}
+
 // AppleSignIn handles Sign in with Apple (Guideline 4.8).
 func (h *AuthHandler) AppleSignIn(c *fiber.Ctx) error {
 	var req dto.AppleSignInRequest
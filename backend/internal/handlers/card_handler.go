@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CardHandler struct {
+	cardService *services.CardService
+}
+
+func NewCardHandler(cardService *services.CardService) *CardHandler {
+	return &CardHandler{cardService: cardService}
+}
+
+// CreateCard handles POST /snaps/streak/cards — renders (or reuses) a milestone card and returns its share link.
+func (h *CardHandler) CreateCard(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.MilestoneCardRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Invalid request body",
+			})
+		}
+	}
+
+	card, err := h.cardService.CreateCard(userID, req.Milestone)
+	if err != nil {
+		if errors.Is(err, services.ErrMilestoneNotReached) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "You haven't reached this milestone yet",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to create milestone card",
+		})
+	}
+
+	baseURL := c.Protocol() + "://" + c.Hostname()
+	return c.Status(fiber.StatusCreated).JSON(toCardResponse(card, baseURL))
+}
+
+// ListCards handles GET /snaps/streak/cards — returns the user's active share links.
+func (h *CardHandler) ListCards(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	cards, err := h.cardService.ListCards(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch milestone cards",
+		})
+	}
+
+	baseURL := c.Protocol() + "://" + c.Hostname()
+	responses := make([]dto.MilestoneCardResponse, len(cards))
+	for i := range cards {
+		responses[i] = toCardResponse(&cards[i], baseURL)
+	}
+	return c.JSON(fiber.Map{"cards": responses})
+}
+
+// RevokeCard handles DELETE /snaps/streak/cards/:id — disables the card's public share link.
+func (h *CardHandler) RevokeCard(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	cardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid card ID",
+		})
+	}
+
+	if err := h.cardService.RevokeCard(userID, cardID); err != nil {
+		if errors.Is(err, services.ErrCardNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: "Card not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to revoke card",
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Share link revoked"})
+}
+
+// GetSharedCard handles GET /share/cards/:token — public PNG download for a share link.
+func (h *CardHandler) GetSharedCard(c *fiber.Ctx) error {
+	path, err := h.cardService.GetSharedCard(c.Params("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrCardNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: "Card not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to load card",
+		})
+	}
+
+	// Revocation must take effect immediately, so don't let shared caches keep the image
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.SendFile(path)
+}
+
+func toCardResponse(card *models.MilestoneCard, baseURL string) dto.MilestoneCardResponse {
+	return dto.MilestoneCardResponse{
+		ID:        card.ID.String(),
+		Milestone: card.Milestone,
+		ShareURL:  baseURL + "/api/share/cards/" + card.Token,
+		CreatedAt: card.CreatedAt,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -147,14 +148,20 @@ func (h *ModerationHandler) ActionReport(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.moderationService.ActionReport(reportID, &req); err != nil {
-		if errors.Is(err, services.ErrReportNotFound) {
+	audit := newAuditEntry(c, models.AuditReportActioned, "report", reportID.String())
+	if _, _, err := h.moderationService.ActionReport(reportID, &req, audit); err != nil {
+		switch {
+		case errors.Is(err, services.ErrReportNotFound):
 			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
 				Error: true, Message: err.Error(),
 			})
+		case errors.Is(err, services.ErrInvalidReportStatus):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
 		}
-		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
-			Error: true, Message: err.Error(),
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to update report",
 		})
 	}
 
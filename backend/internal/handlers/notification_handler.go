@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// ListNotifications handles GET /notifications — returns the latest notifications and the unread count.
+func (h *NotificationHandler) ListNotifications(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	notifications, unread, err := h.notificationService.List(userID, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"notifications": notifications,
+		"unread_count":  unread,
+	})
+}
+
+// MarkAllRead handles POST /notifications/read — marks every notification as read.
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to mark notifications as read",
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Notifications marked as read"})
+}
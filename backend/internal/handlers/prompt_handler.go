@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PromptHandler struct {
+	promptService *services.PromptService
+}
+
+func NewPromptHandler(promptService *services.PromptService) *PromptHandler {
+	return &PromptHandler{promptService: promptService}
+}
+
+// GetToday handles GET /prompts/today — returns today's prompt in the user's time zone.
+// The locale comes from ?locale= or the Accept-Language header, defaulting to English.
+func (h *PromptHandler) GetToday(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	locale := c.Query("locale", c.Get("Accept-Language"))
+
+	prompt, date, err := h.promptService.TodayPrompt(userID, locale, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrNoPrompts) || errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch today's prompt",
+		})
+	}
+
+	return c.JSON(dto.PromptResponse{
+		ID:       prompt.ID.String(),
+		Text:     prompt.Text,
+		Emoji:    prompt.Emoji,
+		Category: prompt.Category,
+		Locale:   prompt.Locale,
+		Date:     date,
+	})
+}
+
+// GetPromptSnaps handles GET /prompts/:id/snaps — browses snaps answering a prompt.
+func (h *PromptHandler) GetPromptSnaps(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	promptID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid prompt ID",
+		})
+	}
+
+	if _, err := h.promptService.GetPrompt(promptID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: "Prompt not found",
+		})
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	snaps, total, err := h.promptService.GetPromptSnaps(promptID, userID, limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch snaps",
+		})
+	}
+
+	baseURL := c.Protocol() + "://" + c.Hostname()
+	snapResponses := make([]dto.SnapResponse, len(snaps))
+	for i := range snaps {
+		snapResponses[i] = toSnapResponse(&snaps[i], baseURL)
+	}
+
+	return c.JSON(dto.SnapsListResponse{
+		Snaps: snapResponses,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	})
+}
+
+// --- Admin endpoints ---
+
+// ListPrompts returns prompts, optionally filtered by ?locale=.
+func (h *PromptHandler) ListPrompts(c *fiber.Ctx) error {
+	locale := c.Query("locale", "")
+	limit, offset, ok := pageParams(c, 50, 200)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid offset",
+		})
+	}
+
+	prompts, total, err := h.promptService.ListPrompts(locale, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch prompts",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"prompts": prompts,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// CreatePrompt adds a prompt to the rotation and/or schedules it for a date.
+func (h *PromptHandler) CreatePrompt(c *fiber.Ctx) error {
+	var req dto.PromptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	audit := newAuditEntry(c, models.AuditPromptCreated, "prompt", "")
+	prompt, err := h.promptService.CreatePrompt(&req, audit)
+	if err != nil {
+		if errors.Is(err, services.ErrPromptScheduled) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(prompt)
+}
+
+// UpdatePrompt edits a prompt's text, schedule or rotation membership.
+func (h *PromptHandler) UpdatePrompt(c *fiber.Ctx) error {
+	promptID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid prompt ID",
+		})
+	}
+
+	var req dto.PromptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	audit := newAuditEntry(c, models.AuditPromptUpdated, "prompt", promptID.String())
+	_, prompt, err := h.promptService.UpdatePrompt(promptID, &req, audit)
+	if err != nil {
+		if errors.Is(err, services.ErrPromptNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrPromptScheduled) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+
+	return c.JSON(prompt)
+}
+
+// DeletePrompt removes a prompt.
+func (h *PromptHandler) DeletePrompt(c *fiber.Ctx) error {
+	promptID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid prompt ID",
+		})
+	}
+
+	audit := newAuditEntry(c, models.AuditPromptDeleted, "prompt", promptID.String())
+	_, err = h.promptService.DeletePrompt(promptID, audit)
+	if err != nil {
+		if errors.Is(err, services.ErrPromptNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to delete prompt",
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Prompt deleted"})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RecapHandler struct {
+	recapService *services.RecapService
+}
+
+func NewRecapHandler(recapService *services.RecapService) *RecapHandler {
+	return &RecapHandler{recapService: recapService}
+}
+
+// ListRecaps handles GET /recaps — returns the user's monthly and yearly recaps, newest first.
+func (h *RecapHandler) ListRecaps(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	recaps, err := h.recapService.ListRecaps(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch recaps",
+		})
+	}
+
+	baseURL := c.Protocol() + "://" + c.Hostname()
+	responses := make([]dto.RecapResponse, 0, len(recaps))
+	for i := range recaps {
+		resp, err := toRecapResponse(&recaps[i], baseURL)
+		if err != nil {
+			continue
+		}
+		responses = append(responses, resp)
+	}
+
+	return c.JSON(fiber.Map{"recaps": responses})
+}
+
+// GetRecap handles GET /recaps/:id — returns one recap.
+func (h *RecapHandler) GetRecap(c *fiber.Ctx) error {
+	recap, err := h.loadRecap(c)
+	if err != nil {
+		return err
+	}
+	if recap == nil {
+		return nil
+	}
+
+	resp, err := toRecapResponse(recap, c.Protocol()+"://"+c.Hostname())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to read recap",
+		})
+	}
+	return c.JSON(resp)
+}
+
+// GetRecapImage handles GET /recaps/:id/image — returns the recap collage PNG.
+func (h *RecapHandler) GetRecapImage(c *fiber.Ctx) error {
+	recap, err := h.loadRecap(c)
+	if err != nil {
+		return err
+	}
+	if recap == nil {
+		return nil
+	}
+	if recap.ImagePath == "" {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: "Recap has no image",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.SendFile(recap.ImagePath)
+}
+
+// loadRecap resolves the :id recap of the caller. On failure it writes the error response and returns nil.
+func (h *RecapHandler) loadRecap(c *fiber.Ctx) (*models.Recap, error) {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	recapID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid recap ID",
+		})
+	}
+
+	recap, err := h.recapService.GetRecap(userID, recapID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecapNotFound) {
+			return nil, c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: "Recap not found",
+			})
+		}
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch recap",
+		})
+	}
+	return recap, nil
+}
+
+// toRecapResponse decodes a stored recap summary and points image_url at the collage endpoint.
+func toRecapResponse(recap *models.Recap, baseURL string) (dto.RecapResponse, error) {
+	resp := dto.RecapResponse{
+		ID:        recap.ID.String(),
+		Period:    recap.Period,
+		PeriodKey: recap.PeriodKey,
+		Title:     recap.PeriodKey,
+		CreatedAt: recap.CreatedAt,
+	}
+	if recap.Period == models.RecapMonth {
+		if t, err := time.Parse("2006-01", recap.PeriodKey); err == nil {
+			resp.Title = t.Format("January 2006")
+		}
+	}
+	if recap.ImagePath != "" {
+		resp.ImageURL = baseURL + "/api/recaps/" + recap.ID.String() + "/image"
+	}
+	if err := json.Unmarshal([]byte(recap.Summary), &resp.Summary); err != nil {
+		return resp, err
+	}
+
+	for i := range resp.Summary.TopLikedSnaps {
+		if url := resp.Summary.TopLikedSnaps[i].ImageURL; len(url) > 0 && url[0] == '/' {
+			resp.Summary.TopLikedSnaps[i].ImageURL = baseURL + url
+		}
+	}
+	return resp, nil
+}
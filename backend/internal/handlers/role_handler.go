@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// SetRole handles PUT /admin/users/:id/role — lets admins grant or revoke moderator and admin roles.
+func (h *RoleHandler) SetRole(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid user ID",
+		})
+	}
+
+	var req dto.SetRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	audit := newAuditEntry(c, models.AuditRoleChanged, "user", userID.String())
+	user, _, err := h.roleService.SetRole(userID, req.Role, audit)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrLastAdmin):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrUserNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to update role",
+		})
+	}
+
+	return c.JSON(fiber.Map{"id": user.ID, "email": user.Email, "role": user.Role})
+}
@@ -5,19 +5,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type SnapHandler struct {
-	snapService *services.SnapService
+	snapService   *services.SnapService
+	windowService *services.WindowService
+	freezeService *services.FreezeService
 }
 
-func NewSnapHandler(snapService *services.SnapService) *SnapHandler {
-	return &SnapHandler{snapService: snapService}
+func NewSnapHandler(snapService *services.SnapService, windowService *services.WindowService, freezeService *services.FreezeService) *SnapHandler {
+	return &SnapHandler{snapService: snapService, windowService: windowService, freezeService: freezeService}
 }
 
 // CreateSnap handles POST /snaps — creates a new snap with multipart/form-data image upload.
@@ -58,10 +62,21 @@ func (h *SnapHandler) CreateSnap(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse caption and filter from form fields
+	// Parse caption, filter and optional prompt from form fields
 	caption := c.FormValue("caption", "")
 	filter := c.FormValue("filter", "none")
 
+	var promptID *uuid.UUID
+	if raw := c.FormValue("prompt_id", ""); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Invalid prompt ID",
+			})
+		}
+		promptID = &id
+	}
+
 	// Generate unique filename
 	fileExt := filepath.Ext(file.Filename)
 	if fileExt == "" {
@@ -78,34 +93,38 @@ func (h *SnapHandler) CreateSnap(c *fiber.Ctx) error {
 		})
 	}
 
+	// Downsize unless the user's plan keeps original resolution
+	if err := h.snapService.FitImageToPlan(userID, savePath); err != nil {
+		os.Remove(savePath)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to process image",
+		})
+	}
+
 	// Generate accessible URL path
 	imageURL := fmt.Sprintf("/uploads/snaps/%s", filename)
 
 	// Create snap via service (handles streak update too)
-	snap, err := h.snapService.CreateSnap(userID, imageURL, caption, filter)
+	snap, err := h.snapService.CreateSnap(userID, imageURL, caption, filter, promptID)
 	if err != nil {
 		// Clean up uploaded file if database save fails
 		os.Remove(savePath)
-		if errors.Is(err, services.ErrInvalidFilter) {
+		if errors.Is(err, services.ErrInvalidFilter) || errors.Is(err, services.ErrPromptNotFound) || errors.Is(err, services.ErrPromptNotToday) {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
 				Error: true, Message: err.Error(),
 			})
 		}
+		if errors.Is(err, services.ErrFilterNotInPlan) {
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
 			Error: true, Message: "Failed to create snap",
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(dto.SnapResponse{
-		ID:        snap.ID.String(),
-		UserID:    snap.UserID.String(),
-		ImageURL:  snap.ImageURL,
-		Caption:   snap.Caption,
-		Filter:    snap.Filter,
-		SnapDate:  snap.SnapDate,
-		LikeCount: snap.LikeCount,
-		CreatedAt: snap.CreatedAt,
-	})
+	return c.Status(fiber.StatusCreated).JSON(toSnapResponse(snap, ""))
 }
 
 // GetMySnaps handles GET /snaps — returns paginated snaps for the authenticated user.
@@ -126,8 +145,19 @@ func (h *SnapHandler) GetMySnaps(c *fiber.Ctx) error {
 		limit = 20
 	}
 
+	var promptID *uuid.UUID
+	if raw := c.Query("prompt_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Invalid prompt ID",
+			})
+		}
+		promptID = &id
+	}
+
 	offset := (page - 1) * limit
-	snaps, total, err := h.snapService.GetUserSnaps(userID, limit, offset)
+	snaps, total, err := h.snapService.GetUserSnaps(userID, promptID, limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
 			Error: true, Message: "Failed to fetch snaps",
@@ -137,21 +167,8 @@ func (h *SnapHandler) GetMySnaps(c *fiber.Ctx) error {
 	// Build full URLs for images
 	baseURL := c.Protocol() + "://" + c.Hostname()
 	snapResponses := make([]dto.SnapResponse, len(snaps))
-	for i, snap := range snaps {
-		imageURL := snap.ImageURL
-		if len(imageURL) > 0 && imageURL[0] == '/' {
-			imageURL = baseURL + imageURL
-		}
-		snapResponses[i] = dto.SnapResponse{
-			ID:        snap.ID.String(),
-			UserID:    snap.UserID.String(),
-			ImageURL:  imageURL,
-			Caption:   snap.Caption,
-			Filter:    snap.Filter,
-			SnapDate:  snap.SnapDate,
-			LikeCount: snap.LikeCount,
-			CreatedAt: snap.CreatedAt,
-		}
+	for i := range snaps {
+		snapResponses[i] = toSnapResponse(&snaps[i], baseURL)
 	}
 
 	return c.JSON(dto.SnapsListResponse{
@@ -196,7 +213,8 @@ func (h *SnapHandler) GetStreak(c *fiber.Ctx) error {
 	})
 }
 
-// AddFreeze handles POST /snaps/streak/freeze — adds a streak freeze to the user's account.
+// AddFreeze handles POST /snaps/streak/freeze — lets premium subscribers claim their monthly streak freezes.
+// Free users earn freezes at 30-day milestones or buy them in the app.
 func (h *SnapHandler) AddFreeze(c *fiber.Ctx) error {
 	userID, err := extractUserID(c)
 	if err != nil {
@@ -205,32 +223,71 @@ func (h *SnapHandler) AddFreeze(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.snapService.AddStreakFreeze(userID)
+	streak, err := h.freezeService.ClaimPremiumFreeze(userID)
 	if err != nil {
-		if err.Error() == "maximum freezes reached (3)" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "You already have the maximum of 3 freezes",
+		if errors.Is(err, services.ErrPremiumRequired) {
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
 			})
 		}
-		if err.Error() == "no streak record found for user" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "No streak record found. Start your streak first!",
+		if errors.Is(err, services.ErrFreezeCapReached) || errors.Is(err, services.ErrMonthlyFreezeLimit) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
 			})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add streak freeze",
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to add streak freeze",
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Streak freeze added successfully",
-		"data": fiber.Map{
-			"freezes_available": 1,
-		},
+	return c.JSON(dto.FreezeResponse{
+		Message:          "Streak freeze added successfully",
+		FreezesAvailable: streak.FreezesAvailable,
+		FreezesUsed:      streak.FreezesUsed,
+		CurrentStreak:    streak.CurrentStreak,
 	})
 }
 
-// GetSnapCalendar handles GET /snaps/calendar — returns an array of date strings for the user's snap activity.
+// GetStreakHistory handles GET /snaps/streak/history — returns the current streak and all past streaks.
+func (h *SnapHandler) GetStreakHistory(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	history, err := h.snapService.GetStreakHistory(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch streak history",
+		})
+	}
+
+	return c.JSON(history)
+}
+
+// GetFreezeHistory handles GET /snaps/streak/freezes — returns the freeze ledger (grants and uses).
+func (h *SnapHandler) GetFreezeHistory(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	entries, err := h.freezeService.History(userID, 50)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch freeze history",
+		})
+	}
+
+	return c.JSON(fiber.Map{"transactions": entries})
+}
+
+// GetSnapCalendar handles GET /snaps/calendar — returns one entry per day with the snap count,
+// first snap thumbnail and status. Accepts from/to (YYYY-MM-DD), year, or days (ending today).
 func (h *SnapHandler) GetSnapCalendar(c *fiber.Ctx) error {
 	userID, err := extractUserID(c)
 	if err != nil {
@@ -240,23 +297,66 @@ func (h *SnapHandler) GetSnapCalendar(c *fiber.Ctx) error {
 	}
 
 	days := c.QueryInt("days", 35)
-	if days < 7 {
-		days = 7
+	if days < 1 || days > services.MaxCalendarDays {
+		days = 35
 	}
-	if days > 90 {
-		days = 90
+
+	from, to := c.Query("from"), c.Query("to")
+	if year := c.QueryInt("year", 0); year > 0 {
+		from, to = fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year)
 	}
 
-	dates, err := h.snapService.GetSnapDates(userID, days)
+	calendar, err := h.snapService.GetCalendar(userID, from, to, days)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Invalid date range: use YYYY-MM-DD dates at most %d days apart", services.MaxCalendarDays),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve calendar data",
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"dates": dates,
-		"days":  days,
+	baseURL := c.Protocol() + "://" + c.Hostname()
+	for i := range calendar.Calendar {
+		if url := calendar.Calendar[i].ThumbnailURL; len(url) > 0 && url[0] == '/' {
+			calendar.Calendar[i].ThumbnailURL = baseURL + url
+		}
+	}
+
+	return c.JSON(calendar)
+}
+
+// GetSnapWindow handles GET /snaps/window — returns today's 2-hour snap window in the user's time zone.
+func (h *SnapHandler) GetSnapWindow(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	now := time.Now()
+	window, loc, err := h.windowService.CurrentWindow(userID, now)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: "User not found",
+		})
+	}
+
+	var remaining int64
+	if window.Contains(now) {
+		remaining = int64(window.ClosesAt.Sub(now).Seconds())
+	}
+
+	return c.JSON(dto.SnapWindowResponse{
+		Date:             window.Date,
+		Timezone:         loc.String(),
+		OpensAt:          window.OpensAt,
+		ClosesAt:         window.ClosesAt,
+		IsOpen:           window.Contains(now),
+		RemainingSeconds: remaining,
 	})
 }
 
@@ -314,3 +414,67 @@ func (h *SnapHandler) LikeSnap(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{"message": "Snap liked"})
 }
+
+// toSnapResponse maps a snap to its API shape. Relative image paths are prefixed with baseURL when given.
+func toSnapResponse(snap *models.Snap, baseURL string) dto.SnapResponse {
+	imageURL := snap.ImageURL
+	if baseURL != "" && len(imageURL) > 0 && imageURL[0] == '/' {
+		imageURL = baseURL + imageURL
+	}
+
+	var promptID *string
+	if snap.PromptID != nil {
+		id := snap.PromptID.String()
+		promptID = &id
+	}
+
+	return dto.SnapResponse{
+		ID:           snap.ID.String(),
+		UserID:       snap.UserID.String(),
+		ImageURL:     imageURL,
+		Caption:      snap.Caption,
+		Filter:       snap.Filter,
+		SnapDate:     snap.SnapDate,
+		LikeCount:    snap.LikeCount,
+		WindowStatus: snap.WindowStatus,
+		PromptID:     promptID,
+		CreatedAt:    snap.CreatedAt,
+	}
+}
+
+// --- Admin endpoints ---
+
+// RebuildStreak handles POST /admin/streaks/:userId/rebuild — recomputes one user's streak from
+// history. Defaults to a dry run; pass ?dry_run=false to apply.
+func (h *SnapHandler) RebuildStreak(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid user ID",
+		})
+	}
+
+	audit := newAuditEntry(c, models.AuditStreakRebuilt, "user", "")
+	result, err := h.snapService.RebuildStreak(userID, c.QueryBool("dry_run", true), audit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to rebuild streak",
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// RebuildAllStreaks handles POST /admin/streaks/rebuild — recomputes every user's streak.
+// Defaults to a dry run; pass ?dry_run=false to apply.
+func (h *SnapHandler) RebuildAllStreaks(c *fiber.Ctx) error {
+	audit := newAuditEntry(c, models.AuditStreakRebuilt, "user", "")
+	report, err := h.snapService.RebuildAllStreaks(c.QueryBool("dry_run", true), audit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to rebuild streaks",
+		})
+	}
+
+	return c.JSON(report)
+}
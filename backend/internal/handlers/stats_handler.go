@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type StatsHandler struct {
+	statsService *services.StatsService
+}
+
+func NewStatsHandler(statsService *services.StatsService) *StatsHandler {
+	return &StatsHandler{statsService: statsService}
+}
+
+// GetStats handles GET /snaps/stats — returns personal snap statistics in the user's time zone.
+func (h *StatsHandler) GetStats(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	stats, err := h.statsService.GetStats(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch statistics",
+		})
+	}
+
+	return c.JSON(stats)
+}
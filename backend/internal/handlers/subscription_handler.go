@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SubscriptionHandler struct {
+	entitlementService  *services.EntitlementService
+	planService         *services.PlanService
+	subscriptionService *services.SubscriptionService
+	auditService        *services.AuditService
+}
+
+func NewSubscriptionHandler(entitlementService *services.EntitlementService, planService *services.PlanService, subscriptionService *services.SubscriptionService, auditService *services.AuditService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		entitlementService:  entitlementService,
+		planService:         planService,
+		subscriptionService: subscriptionService,
+		auditService:        auditService,
+	}
+}
+
+// GetSubscription handles GET /subscription — returns the user's entitlements, subscription status and plan limits.
+func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	status, err := h.entitlementService.Status(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch subscription",
+		})
+	}
+	limits, err := h.planService.Limits(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch subscription",
+		})
+	}
+	status.Plan = &dto.PlanResponse{
+		Name:                limits.Plan,
+		MaxFreezes:          limits.MaxFreezes,
+		MonthlyFreezeClaims: limits.MonthlyFreezeClaims,
+		CalendarHistoryDays: limits.CalendarHistoryDays,
+		OriginalResolution:  limits.OriginalResolution,
+		Filters:             limits.Filters,
+	}
+	if !limits.OriginalResolution {
+		status.Plan.MaxImageDimension = limits.MaxImageDimension
+	}
+
+	return c.JSON(status)
+}
+
+// Identify handles POST /subscription/identify — registers the RevenueCat app user ID the client
+// uses for the signed-in user (call after login) and links subscriptions bought under it.
+func (h *SubscriptionHandler) Identify(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.IdentifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+	req.AppUserID = strings.TrimSpace(req.AppUserID)
+	if req.AppUserID == "" || len(req.AppUserID) > 255 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "app_user_id is required",
+		})
+	}
+	if len(req.Aliases) > 20 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Too many aliases",
+		})
+	}
+
+	audit := newAuditEntry(c, models.AuditAppUserIdentified, "user", userID.String())
+	linked, err := h.subscriptionService.Identify(c.UserContext(), userID, req.AppUserID, req.Aliases, audit)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAppUserIDTaken):
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidAppUserID):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrAppUserIDNotVerified):
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to register RevenueCat ID",
+		})
+	}
+
+	return c.JSON(fiber.Map{"linked_subscriptions": linked})
+}
+
+// ReconcileOrphans lets admins attach subscriptions stored without a user to their owners.
+func (h *SubscriptionHandler) ReconcileOrphans(c *fiber.Ctx) error {
+	report, err := h.subscriptionService.ReconcileOrphanedSubscriptions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to reconcile subscriptions",
+		})
+	}
+	if err := recordAudit(c, h.auditService, models.AuditOrphansReconciled, "subscription", "", nil, report); err != nil {
+		return auditFailed(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// Reconcile lets admins compare every subscription against the RevenueCat API and fix drift.
+func (h *SubscriptionHandler) Reconcile(c *fiber.Ctx) error {
+	report, err := h.subscriptionService.ReconcileAll(c.UserContext())
+	if err != nil {
+		return reconcileError(c, err)
+	}
+	if err := recordAudit(c, h.auditService, models.AuditSubscriptionReconciled, "subscription", "", nil, report); err != nil {
+		return auditFailed(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// ReconcileOne reconciles a single subscription against the RevenueCat API.
+func (h *SubscriptionHandler) ReconcileOne(c *fiber.Ctx) error {
+	subscriptionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid subscription ID",
+		})
+	}
+
+	report, err := h.subscriptionService.ReconcileSubscription(c.UserContext(), subscriptionID)
+	if err != nil {
+		return reconcileError(c, err)
+	}
+	if err := recordAudit(c, h.auditService, models.AuditSubscriptionReconciled, "subscription", subscriptionID.String(), nil, report); err != nil {
+		return auditFailed(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+func reconcileError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSubscriptionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrRevenueCatNotConfigured):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to reconcile subscriptions",
+		})
+	}
+}
+
+// ListCorrections lets admins review fixes made by reconciliation, e.g. ?subscription_id=...
+func (h *SubscriptionHandler) ListCorrections(c *fiber.Ctx) error {
+	subscriptionID := c.Query("subscription_id", "")
+	limit, offset, ok := pageParams(c, 20, 100)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid offset",
+		})
+	}
+	if subscriptionID != "" {
+		if _, err := uuid.Parse(subscriptionID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Invalid subscription ID",
+			})
+		}
+	}
+
+	corrections, total, err := h.subscriptionService.ListCorrections(subscriptionID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch corrections",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"corrections": corrections,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type TimelapseHandler struct {
+	timelapseService *services.TimelapseService
+}
+
+func NewTimelapseHandler(timelapseService *services.TimelapseService) *TimelapseHandler {
+	return &TimelapseHandler{timelapseService: timelapseService}
+}
+
+// CreateTimelapse handles POST /snaps/timelapse — queues a time-lapse and returns the job to poll.
+func (h *TimelapseHandler) CreateTimelapse(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.TimelapseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	job, err := h.timelapseService.CreateJob(userID, req.From, req.To, req.Format)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidDateRange):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: fmt.Sprintf("from and to must be YYYY-MM-DD dates at most %d days apart", services.MaxCalendarDays),
+			})
+		case errors.Is(err, services.ErrTimelapseWebP):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrTimelapseFormat):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: fmt.Sprintf("Unsupported format, available: %v", h.timelapseService.Formats()),
+			})
+		case errors.Is(err, services.ErrTimelapseInProgress):
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to create time-lapse",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(toTimelapseResponse(job, c.Protocol()+"://"+c.Hostname()))
+}
+
+// GetTimelapse handles GET /snaps/timelapse/:id — returns job status and progress.
+func (h *TimelapseHandler) GetTimelapse(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid time-lapse ID",
+		})
+	}
+
+	job, err := h.timelapseService.GetJob(userID, jobID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: "Time-lapse not found",
+		})
+	}
+
+	return c.JSON(toTimelapseResponse(job, c.Protocol()+"://"+c.Hostname()))
+}
+
+// DownloadTimelapse handles GET /snaps/timelapse/:id/download — returns the finished GIF or MP4.
+func (h *TimelapseHandler) DownloadTimelapse(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid time-lapse ID",
+		})
+	}
+
+	job, err := h.timelapseService.GetOutput(userID, jobID)
+	if err != nil {
+		if errors.Is(err, services.ErrTimelapseNotReady) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+				Error: true, Message: "Time-lapse is not ready yet",
+			})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: "Time-lapse not found",
+		})
+	}
+
+	return c.Download(job.OutputPath, "timelapse_"+job.FromDate+"_"+job.ToDate+"."+job.Format)
+}
+
+func toTimelapseResponse(job *models.TimelapseJob, baseURL string) dto.TimelapseResponse {
+	resp := dto.TimelapseResponse{
+		ID:          job.ID.String(),
+		Format:      job.Format,
+		From:        job.FromDate,
+		To:          job.ToDate,
+		Status:      job.Status,
+		Progress:    job.Progress,
+		FrameCount:  job.FrameCount,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+	}
+	if job.Status == models.TimelapseDone {
+		resp.DownloadURL = baseURL + "/api/snaps/timelapse/" + job.ID.String() + "/download"
+	}
+	return resp
+}
@@ -1,36 +1,29 @@
 package handlers
 
 import (
-	"crypto/subtle"
+	"errors"
 
-	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type WebhookHandler struct {
 	subscriptionService *services.SubscriptionService
-	cfg                 *config.Config
+	auditService        *services.AuditService
 }
 
-func NewWebhookHandler(subscriptionService *services.SubscriptionService, cfg *config.Config) *WebhookHandler {
+func NewWebhookHandler(subscriptionService *services.SubscriptionService, auditService *services.AuditService) *WebhookHandler {
 	return &WebhookHandler{
 		subscriptionService: subscriptionService,
-		cfg:                 cfg,
+		auditService:        auditService,
 	}
 }
 
+// HandleRevenueCat applies a RevenueCat webhook. The sender is verified by middleware.VerifyWebhook.
 func (h *WebhookHandler) HandleRevenueCat(c *fiber.Ctx) error {
-	// Verify authorization header using constant-time comparison
-	authHeader := c.Get("Authorization")
-	if subtle.ConstantTimeCompare([]byte(authHeader), []byte(h.cfg.RevenueCatWebhookAuth)) != 1 {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
-			Error:   true,
-			Message: "Unauthorized",
-		})
-	}
-
 	var webhook dto.RevenueCatWebhook
 	if err := c.BodyParser(&webhook); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
@@ -39,7 +32,14 @@ func (h *WebhookHandler) HandleRevenueCat(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.subscriptionService.HandleWebhookEvent(&webhook.Event); err != nil {
+	// Stored and deduplicated by event ID; a failure returns 500 so RevenueCat retries
+	if err := h.subscriptionService.ProcessWebhook(c.Body(), &webhook.Event); err != nil {
+		if errors.Is(err, services.ErrMissingEventID) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   true,
+				Message: "Invalid webhook payload",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
 			Error:   true,
 			Message: "Failed to process webhook event",
@@ -48,3 +48,67 @@ func (h *WebhookHandler) HandleRevenueCat(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{"received": true})
 }
+
+// ListEvents lets admins browse the webhook event log, e.g. ?status=failed.
+func (h *WebhookHandler) ListEvents(c *fiber.Ctx) error {
+	status := c.Query("status", "")
+	limit, offset, ok := pageParams(c, 20, 100)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid offset",
+		})
+	}
+
+	events, total, err := h.subscriptionService.ListWebhookEvents(status, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch webhook events",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"events": events,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// ReplayEvent lets admins re-run a logged (typically failed) event from its stored payload.
+func (h *WebhookHandler) ReplayEvent(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid event ID",
+		})
+	}
+
+	before, event, err := h.subscriptionService.ReplayWebhookEvent(eventID)
+	if event != nil {
+		// The replay runs in the webhook pipeline's own transactions, so it is audited afterwards
+		if err := recordAudit(c, h.auditService, models.AuditWebhookReplayed, "webhook_event", event.ID.String(),
+			webhookAuditFields(before), webhookAuditFields(event)); err != nil {
+			return auditFailed(c, err)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookEventNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		if event == nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+				Error: true, Message: "Failed to replay webhook event",
+			})
+		}
+		// Replayed but failed again; the event carries the error
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(event)
+	}
+
+	return c.JSON(event)
+}
+
+func webhookAuditFields(e *models.WebhookEvent) fiber.Map {
+	return fiber.Map{"status": e.Status, "error": e.Error, "attempts": e.Attempts}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// RequireEntitlement only lets through users who currently have the given RevenueCat
+// entitlement. It must run after JWTProtected.
+func RequireEntitlement(entitlements *services.EntitlementService, entitlement string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := tokenUserID(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error:   true,
+				Message: "Unauthorized",
+			})
+		}
+
+		entitled, err := entitlements.Has(userID, entitlement)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+				Error:   true,
+				Message: "Failed to check subscription",
+			})
+		}
+		if !entitled {
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+				Error:   true,
+				Message: "An active premium subscription is required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// tokenUserID returns the user ID from the "sub" claim of the token set by JWTProtected.
+func tokenUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return uuid.Nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.Nil, false
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(sub)
+	return userID, err == nil
+}
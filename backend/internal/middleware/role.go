@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RequireRole only lets through users whose role includes the given one. The token's role
+// claim rejects ordinary users without a query; the role is then re-checked in the database so
+// demotions apply at once. Promotions apply once the user refreshes their token.
+// It must run after JWTProtected.
+func RequireRole(roles *services.RoleService, role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := tokenUserID(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error:   true,
+				Message: "Unauthorized",
+			})
+		}
+
+		if !models.RoleAtLeast(tokenRole(c), role) {
+			return forbidden(c)
+		}
+		current, err := roles.Role(userID)
+		if err != nil {
+			if errors.Is(err, services.ErrUserNotFound) {
+				return forbidden(c)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+				Error:   true,
+				Message: "Failed to check permissions",
+			})
+		}
+		if !models.RoleAtLeast(current, role) {
+			return forbidden(c)
+		}
+
+		c.Locals("role", current)
+		return c.Next()
+	}
+}
+
+func forbidden(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+		Error:   true,
+		Message: "Insufficient permissions",
+	})
+}
+
+// tokenRole returns the "role" claim of the token set by JWTProtected, or "" if absent.
+func tokenRole(c *fiber.Ctx) string {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
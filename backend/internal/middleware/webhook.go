@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookConfig describes how an inbound provider authenticates its webhooks. Every configured
+// check must pass. Each secret list may hold several entries so secrets can be rotated without
+// downtime: add the new one, switch the provider over, then drop the old one.
+type WebhookConfig struct {
+	Name string // provider name, for logs
+
+	// Shared secret sent verbatim in a header (e.g. RevenueCat's Authorization header)
+	AuthHeader  string
+	AuthSecrets []string
+
+	// HMAC-SHA256 of the raw body, hex encoded, optionally prefixed with "sha256="
+	SignatureHeader string
+	SigningSecrets  []string
+	// When set, the signature covers "<timestamp>.<body>" and the Unix timestamp in this header
+	// must be within Tolerance of now, so captured requests can't be replayed later.
+	TimestampHeader string
+	Tolerance       time.Duration
+
+	// Source IPs or CIDRs; empty allows any. Matched against c.IP(), which is the connecting
+	// address unless the app trusts a proxy to report the client (config.TrustedProxies), so
+	// behind a proxy that isn't configured every request would appear to come from the proxy.
+	AllowedIPs []string
+}
+
+type webhookVerifier struct {
+	cfg      WebhookConfig
+	networks []*net.IPNet
+}
+
+// VerifyWebhook returns middleware that rejects webhooks failing the configured checks.
+// It refuses configurations without any secret, so a missing setting can't leave the endpoint open.
+func VerifyWebhook(cfg WebhookConfig) (fiber.Handler, error) {
+	if len(cfg.AuthSecrets) == 0 && len(cfg.SigningSecrets) == 0 {
+		return nil, fmt.Errorf("%s webhook: no secret configured", cfg.Name)
+	}
+	for _, secret := range append(append([]string{}, cfg.AuthSecrets...), cfg.SigningSecrets...) {
+		if strings.TrimSpace(secret) == "" {
+			return nil, fmt.Errorf("%s webhook: empty secret", cfg.Name)
+		}
+	}
+	if len(cfg.AuthSecrets) > 0 && cfg.AuthHeader == "" {
+		return nil, fmt.Errorf("%s webhook: auth secrets need an auth header", cfg.Name)
+	}
+	if len(cfg.SigningSecrets) > 0 && cfg.SignatureHeader == "" {
+		return nil, fmt.Errorf("%s webhook: signing secrets need a signature header", cfg.Name)
+	}
+	if cfg.TimestampHeader != "" && cfg.Tolerance <= 0 {
+		cfg.Tolerance = 5 * time.Minute
+	}
+
+	v := &webhookVerifier{cfg: cfg}
+	for _, entry := range cfg.AllowedIPs {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s webhook: invalid allowed IP %q", cfg.Name, entry)
+		}
+		v.networks = append(v.networks, network)
+	}
+	return v.handle, nil
+}
+
+func (v *webhookVerifier) handle(c *fiber.Ctx) error {
+	if err := v.verify(c); err != nil {
+		log.Printf("Rejected %s webhook from %s: %v", v.cfg.Name, c.IP(), err)
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error:   true,
+			Message: "Unauthorized",
+		})
+	}
+	return c.Next()
+}
+
+func (v *webhookVerifier) verify(c *fiber.Ctx) error {
+	if len(v.networks) > 0 && !v.allowedIP(net.ParseIP(c.IP())) {
+		return errors.New("source IP not allowed")
+	}
+
+	if len(v.cfg.AuthSecrets) > 0 {
+		header := []byte(c.Get(v.cfg.AuthHeader))
+		matched := 0
+		for _, secret := range v.cfg.AuthSecrets {
+			matched |= subtle.ConstantTimeCompare(header, []byte(secret))
+		}
+		if matched != 1 {
+			return errors.New("invalid auth header")
+		}
+	}
+
+	if len(v.cfg.SigningSecrets) > 0 {
+		signature, err := hex.DecodeString(strings.TrimPrefix(c.Get(v.cfg.SignatureHeader), "sha256="))
+		if err != nil || len(signature) != sha256.Size {
+			return errors.New("missing or malformed signature")
+		}
+
+		var signed []byte
+		if v.cfg.TimestampHeader != "" {
+			raw := c.Get(v.cfg.TimestampHeader)
+			ts, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return errors.New("missing or malformed timestamp")
+			}
+			if math.Abs(time.Since(time.Unix(ts, 0)).Seconds()) > v.cfg.Tolerance.Seconds() {
+				return errors.New("timestamp outside tolerance")
+			}
+			signed = append([]byte(raw+"."), c.Body()...)
+		} else {
+			signed = c.Body()
+		}
+
+		valid := false
+		for _, secret := range v.cfg.SigningSecrets {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(signed)
+			if hmac.Equal(mac.Sum(nil), signature) {
+				valid = true
+			}
+		}
+		if !valid {
+			return errors.New("invalid signature")
+		}
+	}
+	return nil
+}
+
+func (v *webhookVerifier) allowedIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range v.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
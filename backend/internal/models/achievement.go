@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Achievement events evaluated by the achievements engine. There is no "first friend" event:
+// the app has no friends or follow feature yet, so that badge is left out until one exists.
+const (
+	EventSnapCreated     = "snap_created"     // value: total snaps
+	EventStreakExtended  = "streak_extended"  // value: current streak length
+	EventFreezeUsed      = "freeze_used"      // value: total freezes used
+	EventPromptCompleted = "prompt_completed" // value: total snaps answering a prompt
+)
+
+// AchievementEvents lists the events the app fires; definitions for any other event could never be earned.
+var AchievementEvents = []string{EventSnapCreated, EventStreakExtended, EventFreezeUsed, EventPromptCompleted}
+
+// AchievementDefinition describes a badge awarded the first time an event's value reaches Threshold.
+type AchievementDefinition struct {
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Event       string `json:"event"`
+	Threshold   int    `json:"threshold"`
+}
+
+// DefaultAchievements is used unless ACHIEVEMENTS_FILE points to a JSON list of definitions.
+var DefaultAchievements = []AchievementDefinition{
+	{Key: "first_snap", Title: "First Snap", Description: "Post your first snap", Icon: "📸", Event: EventSnapCreated, Threshold: 1},
+	{Key: "snaps_100", Title: "Shutterbug", Description: "Post 100 snaps", Icon: "🎞️", Event: EventSnapCreated, Threshold: 100},
+	{Key: "streak_7", Title: "7 Day Warrior", Description: "Reach a 7-day streak", Icon: "🥉", Event: EventStreakExtended, Threshold: 7},
+	{Key: "streak_30", Title: "30 Day Legend", Description: "Reach a 30-day streak", Icon: "🥈", Event: EventStreakExtended, Threshold: 30},
+	{Key: "streak_100", Title: "100 Day Immortal", Description: "Reach a 100-day streak", Icon: "🥇", Event: EventStreakExtended, Threshold: 100},
+	{Key: "streak_365", Title: "Year of Snaps", Description: "Reach a 365-day streak", Icon: "🏆", Event: EventStreakExtended, Threshold: 365},
+	{Key: "first_recovery", Title: "First Recovery", Description: "Save your streak with a freeze", Icon: "🛡️", Event: EventFreezeUsed, Threshold: 1},
+	{Key: "prompt_master", Title: "Prompt Master", Description: "Answer 10 daily prompts", Icon: "🎯", Event: EventPromptCompleted, Threshold: 10},
+}
+
+// UserAchievement records a badge a user has earned. Each badge is awarded once.
+type UserAchievement struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_achievement" json:"user_id"`
+	AchievementKey string    `gorm:"size:50;not null;uniqueIndex:idx_user_achievement" json:"achievement_key"`
+	EarnedAt       time.Time `gorm:"not null" json:"earned_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited actions.
+const (
+	AuditReportActioned         = "report.actioned"
+	AuditRoleChanged            = "user.role_changed"
+	AuditAccountDeleted         = "user.account_deleted"
+	AuditStreakRebuilt          = "streak.rebuilt"
+	AuditPromptCreated          = "prompt.created"
+	AuditPromptUpdated          = "prompt.updated"
+	AuditPromptDeleted          = "prompt.deleted"
+	AuditWebhookReplayed        = "webhook.replayed"
+	AuditOrphansReconciled      = "subscription.orphans_reconciled"
+	AuditSubscriptionReconciled = "subscription.reconciled"
+	AuditTransactionsBackfilled = "analytics.transactions_backfilled"
+	AuditAppUserIdentified      = "subscription.identified"
+)
+
+// AuditLog is an append-only record of an admin or sensitive user action. Before and After hold
+// the target's relevant fields as JSON. The database rejects updates and deletes (see
+// database.Migrate), and entries outlive deleted accounts.
+type AuditLog struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ActorID    *uuid.UUID `gorm:"type:uuid;index" json:"actor_id,omitempty"` // nil for system actions
+	ActorRole  string     `gorm:"size:20" json:"actor_role,omitempty"`
+	Action     string     `gorm:"size:100;not null;index" json:"action"`
+	TargetType string     `gorm:"size:50;not null;index:idx_audit_target" json:"target_type"`
+	TargetID   string     `gorm:"size:255;index:idx_audit_target" json:"target_id,omitempty"`
+	Before     *string    `gorm:"type:jsonb" json:"-"`
+	After      *string    `gorm:"type:jsonb" json:"-"`
+	RequestID  string     `gorm:"size:100;index" json:"request_id,omitempty"`
+	IP         string     `gorm:"size:64" json:"ip,omitempty"`
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Freeze ledger sources.
+const (
+	FreezeSourceMilestone = "milestone" // earned at every 30-day streak milestone
+	FreezeSourcePurchase  = "purchase"  // bought through RevenueCat
+	FreezeSourcePremium   = "premium"   // monthly premium allowance claimed by the user
+	FreezeSourceUsed      = "used"      // consumed to cover a missed day
+)
+
+// FreezeTransaction is an append-only ledger of streak freeze grants (+) and uses (-).
+// Reference, when set, is unique per user so retried grants (e.g. webhook redeliveries) apply once.
+type FreezeTransaction struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_freeze_tx_user_reference" json:"user_id"`
+	Delta     int       `gorm:"not null" json:"delta"`
+	Source    string    `gorm:"not null;size:20" json:"source"`
+	Reference *string   `gorm:"size:255;uniqueIndex:idx_freeze_tx_user_reference" json:"reference,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FrozenDay is a calendar day (in the user's time zone) that a streak freeze covered.
+type FrozenDay struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_frozen_day_user_date" json:"user_id"`
+	Date      string    `gorm:"size:10;not null;uniqueIndex:idx_frozen_day_user_date" json:"date"` // YYYY-MM-DD
+	CreatedAt time.Time `json:"created_at"`
+}
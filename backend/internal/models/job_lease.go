@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// JobLease is a short-lived, DB-backed lock that lets exactly one replica run a scheduled job.
+type JobLease struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"`
+	Holder    string    `gorm:"not null;size:255" json:"holder"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MilestoneCard is a rendered streak milestone image shared through a public, revocable link.
+// Only one active (unrevoked) card exists per user and milestone; it is reused as a cache.
+type MilestoneCard struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_milestone_card_active,where:revoked_at IS NULL" json:"user_id"`
+	Milestone int        `gorm:"not null;uniqueIndex:idx_milestone_card_active,where:revoked_at IS NULL" json:"milestone"`
+	Token     string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ImagePath string     `gorm:"size:255;not null" json:"-"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types delivered to users in-app and via push/email.
+const (
+	NotificationStreakAtRisk = "streak_at_risk"
+	NotificationSnapWindow   = "daily_window"
+	NotificationStreakBroken = "streak_broken"
+	NotificationAchievement  = "milestone"
+	NotificationRecap        = "recap"
+)
+
+// Notification is an in-app notification; push/email delivery is best effort on top of it.
+type Notification struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type      string     `gorm:"not null;size:30" json:"type"`
+	Title     string     `gorm:"not null;size:100" json:"title"`
+	Body      string     `gorm:"not null;size:300" json:"body"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// NotificationReceipt records that a once-per-day notification of a given type went out
+// for a user's local day. The unique index makes delivery exactly-once across replicas.
+type NotificationReceipt struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_notification_receipt_user_type_day" json:"user_id"`
+	Type      string    `gorm:"size:30;not null;uniqueIndex:idx_notification_receipt_user_type_day" json:"type"`
+	LocalDate string    `gorm:"size:10;not null;uniqueIndex:idx_notification_receipt_user_type_day" json:"local_date"` // YYYY-MM-DD in the user's time zone
+	CreatedAt time.Time `json:"created_at"`
+}
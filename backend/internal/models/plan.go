@@ -0,0 +1,67 @@
+package models
+
+// Plan names.
+const (
+	PlanFree    = "free"
+	PlanPremium = "premium"
+)
+
+// PremiumSnapFilters are only available on plans that list them.
+var PremiumSnapFilters = []string{"film", "golden", "duotone", "glow"}
+
+// AllSnapFilters returns every known filter, free ones first.
+func AllSnapFilters() []string {
+	all := make([]string, 0, len(SnapFilters)+len(PremiumSnapFilters))
+	all = append(all, SnapFilters...)
+	return append(all, PremiumSnapFilters...)
+}
+
+// PlanLimits is one row of the plan-limits table. A user is on the last plan in the table
+// whose entitlement they hold; a plan without an entitlement applies to everyone.
+type PlanLimits struct {
+	Plan                string   `json:"plan"`
+	Entitlement         string   `json:"entitlement,omitempty"`
+	MaxFreezes          int      `json:"max_freezes"`           // earned or claimed freezes held at once; purchases ignore it
+	MonthlyFreezeClaims int      `json:"monthly_freeze_claims"` // freezes that can be claimed per calendar month
+	CalendarHistoryDays int      `json:"calendar_history_days"` // how far back the calendar reaches; 0 = unlimited
+	OriginalResolution  bool     `json:"original_resolution"`   // store uploads as sent
+	MaxImageDimension   int      `json:"max_image_dimension"`   // longest side of stored uploads otherwise
+	Filters             []string `json:"filters"`
+}
+
+// AllowsFilter reports whether snaps on the plan may use filter.
+func (p *PlanLimits) AllowsFilter(filter string) bool {
+	for _, f := range p.Filters {
+		if f == filter {
+			return true
+		}
+	}
+	return false
+}
+
+// FreePlan applies to users without a paid entitlement.
+var FreePlan = PlanLimits{
+	Plan:                PlanFree,
+	MaxFreezes:          3,
+	MonthlyFreezeClaims: 0,
+	CalendarHistoryDays: 90,
+	OriginalResolution:  false,
+	MaxImageDimension:   1440,
+	Filters:             SnapFilters,
+}
+
+// DefaultPlans is the built-in plan-limits table; premiumEntitlement unlocks the premium plan.
+func DefaultPlans(premiumEntitlement string) []PlanLimits {
+	return []PlanLimits{
+		FreePlan,
+		{
+			Plan:                PlanPremium,
+			Entitlement:         premiumEntitlement,
+			MaxFreezes:          10,
+			MonthlyFreezeClaims: 3,
+			CalendarHistoryDays: 0,
+			OriginalResolution:  true,
+			Filters:             AllSnapFilters(),
+		},
+	}
+}
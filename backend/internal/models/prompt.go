@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Prompt is a daily themed photo prompt ("Show your lunch"). A prompt with a ScheduledDate
+// is the prompt for that day in its locale; prompts with InRotation fill days nobody scheduled.
+type Prompt struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Text          string    `gorm:"not null;size:100" json:"text"`
+	Emoji         string    `gorm:"size:10" json:"emoji"`
+	Category      string    `gorm:"size:30" json:"category"` // food, color, view, mood, object, activity
+	Locale        string    `gorm:"not null;size:10;default:'en';uniqueIndex:idx_prompt_locale_date" json:"locale"`
+	ScheduledDate *string   `gorm:"size:10;uniqueIndex:idx_prompt_locale_date" json:"scheduled_date,omitempty"` // YYYY-MM-DD
+	InRotation    bool      `gorm:"not null;default:true" json:"in_rotation"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// DefaultPrompts seeds the rotation when the prompts table is empty.
+var DefaultPrompts = []Prompt{
+	{Text: "Show your lunch", Emoji: "🍕", Category: "food"},
+	{Text: "Something blue", Emoji: "🔵", Category: "color"},
+	{Text: "Your view right now", Emoji: "🌇", Category: "view"},
+	{Text: "What made you smile today", Emoji: "😊", Category: "mood"},
+	{Text: "Your favorite mug", Emoji: "☕", Category: "object"},
+	{Text: "Where you're working from", Emoji: "💻", Category: "view"},
+	{Text: "Something green", Emoji: "🌿", Category: "color"},
+	{Text: "Your shoes today", Emoji: "👟", Category: "object"},
+	{Text: "The sky above you", Emoji: "☁️", Category: "view"},
+	{Text: "What you're reading", Emoji: "📚", Category: "activity"},
+	{Text: "Your snack of choice", Emoji: "🍪", Category: "food"},
+	{Text: "Something red", Emoji: "🔴", Category: "color"},
+	{Text: "Your workout", Emoji: "🏃", Category: "activity"},
+	{Text: "Your mood in one photo", Emoji: "🎭", Category: "mood"},
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recap periods.
+const (
+	RecapMonth = "month"
+	RecapYear  = "year"
+)
+
+// Recap is a generated month or year in review. Summary holds the dto.RecapSummary as JSON;
+// the collage image is stored privately at ImagePath.
+type Recap struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_recap_user_period" json:"user_id"`
+	Period    string    `gorm:"size:10;not null" json:"period"`
+	PeriodKey string    `gorm:"size:7;not null;uniqueIndex:idx_recap_user_period" json:"period_key"` // YYYY-MM or YYYY
+	Summary   string    `gorm:"type:jsonb;not null" json:"-"`
+	ImagePath string    `gorm:"size:255" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
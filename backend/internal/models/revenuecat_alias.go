@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevenueCatAlias maps a RevenueCat app user ID (our user ID, an anonymous
+// "$RCAnonymousID:..." ID, or any alias RevenueCat reports) to one of our users.
+type RevenueCatAlias struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AppUserID string    `gorm:"size:255;not null;uniqueIndex" json:"app_user_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -14,6 +14,8 @@ type Snap struct {
 	Filter      string         `gorm:"type:varchar(50)" json:"filter"`
 	SnapDate    time.Time      `gorm:"index" json:"snap_date"`
 	LikeCount   int            `gorm:"default:0" json:"like_count"`
+	WindowStatus string        `gorm:"type:varchar(10)" json:"window_status"` // "early", "on_time" or "late" relative to the daily snap window
+	PromptID    *uuid.UUID     `gorm:"type:uuid;index" json:"prompt_id,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -30,6 +32,17 @@ type SnapStreak struct {
 	FreezesAvailable int       `json:"freezes_available" gorm:"default:0"`
 	FreezesUsed      int       `json:"freezes_used" gorm:"default:0"`
 	LastFreezeDate   time.Time `json:"last_freeze_date"`
+	StartDate        string    `json:"start_date,omitempty" gorm:"size:10"` // local YYYY-MM-DD the current streak began
+}
+
+// StreakEpoch is a completed (broken) streak. LongestStreak is the maximum over these and the current streak.
+type StreakEpoch struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	StartDate string    `gorm:"size:10;not null" json:"start_date"` // first day of the streak, local YYYY-MM-DD
+	EndDate   string    `gorm:"size:10;not null" json:"end_date"`   // last snapped or frozen day, local YYYY-MM-DD
+	Length    int       `gorm:"not null" json:"length"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 var SnapFilters = []string{"none", "vintage", "warm", "cool", "dramatic", "minimal", "vibrant", "noir"}
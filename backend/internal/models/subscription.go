@@ -1,20 +1,43 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Subscription statuses.
+const (
+	SubscriptionActive      = "active"
+	SubscriptionCancelled   = "cancelled"    // auto-renew off; entitled until CurrentPeriodEnd
+	SubscriptionGracePeriod = "grace_period" // billing issue; store is retrying, entitled until GracePeriodExpiresAt
+	SubscriptionPaused      = "paused"       // Play Store pause; resumes at AutoResumeAt
+	SubscriptionExpired     = "expired"
+)
+
 type Subscription struct {
-	ID                 uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	UserID             uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
-	RevenueCatID       string    `gorm:"index;size:255" json:"revenuecat_id"`
-	ProductID          string    `gorm:"size:255" json:"product_id"`
-	Status             string    `gorm:"not null;default:'inactive';size:50" json:"status"`
-	CurrentPeriodStart time.Time `json:"current_period_start"`
-	CurrentPeriodEnd   time.Time `json:"current_period_end"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
-	User               User      `gorm:"foreignKey:UserID" json:"-"`
+	ID                   uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID               *uuid.UUID `gorm:"type:uuid;index" json:"user_id"` // nil until the buyer is known (see ReconcileOrphanedSubscriptions)
+	RevenueCatID         string     `gorm:"index;size:255" json:"revenuecat_id"`
+	ProductID            string     `gorm:"size:255" json:"product_id"`
+	EntitlementIDs       string     `gorm:"size:500" json:"entitlement_ids"`              // comma-separated RevenueCat entitlement identifiers
+	PendingProductID     string     `gorm:"size:255" json:"pending_product_id,omitempty"` // takes effect at the next renewal
+	Status               string     `gorm:"not null;default:'inactive';size:50" json:"status"`
+	CurrentPeriodStart   time.Time  `json:"current_period_start"`
+	CurrentPeriodEnd     *time.Time `json:"current_period_end"` // nil for purchases that never expire (lifetime)
+	GracePeriodExpiresAt *time.Time `json:"grace_period_expires_at,omitempty"`
+	AutoResumeAt         *time.Time `json:"auto_resume_at,omitempty"`
+	LastEventAtMs        int64      `gorm:"not null;default:0" json:"-"` // event_timestamp_ms of the latest applied event
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+	User                 User       `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// Entitlements returns the entitlement identifiers the subscription unlocks.
+func (s *Subscription) Entitlements() []string {
+	if s.EntitlementIDs == "" {
+		return nil
+	}
+	return strings.Split(s.EntitlementIDs, ",")
 }
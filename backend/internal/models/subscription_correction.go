@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionCorrection logs a field of a subscription that reconciliation against the
+// RevenueCat API found out of date and fixed.
+type SubscriptionCorrection struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SubscriptionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	UserID         *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	RevenueCatID   string     `gorm:"size:255" json:"revenuecat_id"`
+	Field          string     `gorm:"size:50;not null" json:"field"`
+	OldValue       string     `gorm:"size:500" json:"old_value"`
+	NewValue       string     `gorm:"size:500" json:"new_value"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionTransaction records the revenue data of one billing event (purchase, renewal,
+// trial start, refund) from RevenueCat for analytics. Refunds carry a negative price.
+type SubscriptionTransaction struct {
+	ID                       uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID                  string     `gorm:"size:255;not null;uniqueIndex" json:"event_id"` // RevenueCat event ID
+	EventType                string     `gorm:"size:50;not null" json:"event_type"`
+	UserID                   *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"` // cleared when the account is deleted
+	RevenueCatID             string     `gorm:"size:255" json:"revenuecat_id"`
+	OriginalTransactionID    string     `gorm:"size:255;not null;index" json:"original_transaction_id"` // identifies the subscription across renewals
+	TransactionID            string     `gorm:"size:255" json:"transaction_id"`
+	ProductID                string     `gorm:"size:255;index" json:"product_id"`
+	Store                    string     `gorm:"size:50" json:"store"`
+	Environment              string     `gorm:"size:20;index" json:"environment"` // PRODUCTION or SANDBOX
+	PeriodType               string     `gorm:"size:20" json:"period_type"`       // NORMAL, TRIAL, INTRO, PROMOTIONAL
+	IsTrialConversion        bool       `gorm:"not null;default:false" json:"is_trial_conversion"`
+	CountryCode              string     `gorm:"size:2" json:"country_code"`
+	Currency                 string     `gorm:"size:3" json:"currency"`
+	Price                    float64    `gorm:"not null;default:0" json:"price"` // USD
+	PriceInPurchasedCurrency float64    `gorm:"not null;default:0" json:"price_in_purchased_currency"`
+	PurchasedAt              time.Time  `gorm:"not null" json:"purchased_at"`
+	ExpiresAt                *time.Time `json:"expires_at,omitempty"` // nil for non-renewing purchases
+	OccurredAt               time.Time  `gorm:"not null;index" json:"occurred_at"`
+	CreatedAt                time.Time  `json:"created_at"`
+}
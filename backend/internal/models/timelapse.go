@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Time-lapse formats and job statuses.
+const (
+	TimelapseGIF = "gif"
+	TimelapseMP4 = "mp4"
+
+	TimelapseQueued     = "queued"
+	TimelapseProcessing = "processing"
+	TimelapseDone       = "done"
+	TimelapseFailed     = "failed"
+)
+
+// TimelapseJob compiles one frame per snapped day between FromDate and ToDate into a video.
+// Jobs are picked up by the background worker; Progress runs from 0 to 100.
+type TimelapseJob struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Format      string     `gorm:"size:10;not null" json:"format"`
+	FromDate    string     `gorm:"size:10;not null" json:"from_date"` // YYYY-MM-DD, user's time zone
+	ToDate      string     `gorm:"size:10;not null" json:"to_date"`
+	Status      string     `gorm:"size:20;not null;default:'queued';index" json:"status"`
+	Progress    int        `gorm:"not null;default:0" json:"progress"`
+	FrameCount  int        `gorm:"not null;default:0" json:"frame_count"`
+	OutputPath  string     `gorm:"size:255" json:"-"`
+	Error       string     `gorm:"size:255" json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
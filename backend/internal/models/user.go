@@ -7,11 +7,45 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles, from least to most privileged. Each role includes the ones below it.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator" // reviews reports
+	RoleAdmin     = "admin"     // everything under /admin
+)
+
+var roleRanks = map[string]int{RoleUser: 1, RoleModerator: 2, RoleAdmin: 3}
+
+// ValidRole reports whether role is a known role.
+func ValidRole(role string) bool {
+	return roleRanks[role] > 0
+}
+
+// RoleAtLeast reports whether role grants everything required does.
+func RoleAtLeast(role, required string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
 type User struct {
 	ID        uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	Email     string         `gorm:"uniqueIndex;not null;size:255" json:"email"`
 	Password  string         `gorm:"not null" json:"-"`
+	Timezone  string         `gorm:"size:64;not null;default:'UTC'" json:"timezone"` // IANA name, e.g. "Europe/Istanbul"
+	PushToken string         `gorm:"size:255" json:"-"`                              // Expo push token
+	Role      string         `gorm:"size:20;not null;default:'user';index" json:"role"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
+
+// Location returns the user's time zone, falling back to UTC when unset or unknown.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook event processing statuses.
+const (
+	WebhookEventReceived  = "received"
+	WebhookEventProcessed = "processed"
+	WebhookEventSkipped   = "skipped" // older than the subscription state already applied
+	WebhookEventIgnored   = "ignored" // nothing in the app handles it (e.g. an unknown product); replayable
+	WebhookEventFailed    = "failed"
+)
+
+// WebhookEvent is the persisted log of incoming RevenueCat events, keyed by the event's own ID
+// so redeliveries are recognised and each event is applied once.
+type WebhookEvent struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID          string     `gorm:"size:255;not null;uniqueIndex" json:"event_id"`
+	Type             string     `gorm:"size:50;not null;index" json:"type"`
+	AppUserID        string     `gorm:"size:255;index" json:"app_user_id"`
+	EventTimestampMs int64      `json:"event_timestamp_ms"`
+	Payload          string     `gorm:"type:jsonb;not null" json:"payload"`
+	Status           string     `gorm:"size:20;not null;index" json:"status"`
+	Error            string     `gorm:"type:text" json:"error,omitempty"`
+	Attempts         int        `gorm:"not null;default:0" json:"attempts"`
+	ProcessedAt      *time.Time `json:"processed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
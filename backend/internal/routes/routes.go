@@ -4,6 +4,8 @@ import (
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/handlers"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/middleware"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -16,6 +18,20 @@ func Setup(
 	moderationHandler *handlers.ModerationHandler,
 	snapHandler *handlers.SnapHandler,
 	legalHandler *handlers.LegalHandler,
+	notificationHandler *handlers.NotificationHandler,
+	promptHandler *handlers.PromptHandler,
+	achievementHandler *handlers.AchievementHandler,
+	cardHandler *handlers.CardHandler,
+	statsHandler *handlers.StatsHandler,
+	recapHandler *handlers.RecapHandler,
+	timelapseHandler *handlers.TimelapseHandler,
+	subscriptionHandler *handlers.SubscriptionHandler,
+	analyticsHandler *handlers.AnalyticsHandler,
+	roleHandler *handlers.RoleHandler,
+	auditHandler *handlers.AuditHandler,
+	entitlements *services.EntitlementService,
+	roles *services.RoleService,
+	revenueCatWebhook fiber.Handler,
 ) {
 	api := app.Group("/api")
 
@@ -26,6 +42,9 @@ func Setup(
 	api.Get("/legal/privacy", legalHandler.PrivacyPolicy)
 	api.Get("/legal/terms", legalHandler.TermsOfService)
 
+	// Milestone card share links (public, revocable)
+	api.Get("/share/cards/:token", cardHandler.GetSharedCard)
+
 	// Auth (public)
 	auth := api.Group("/auth")
 	auth.Post("/register", authHandler.Register)
@@ -37,29 +56,79 @@ func Setup(
 	protected := api.Group("", middleware.JWTProtected(cfg))
 	protected.Post("/auth/logout", authHandler.Logout)
 	protected.Get("/auth/profile", authHandler.GetProfile)
+	protected.Put("/auth/profile", authHandler.UpdateProfile)
 	protected.Delete("/auth/account", authHandler.DeleteAccount) // Account deletion (Guideline 5.1.1)
 
+	// Subscription status (protected)
+	protected.Get("/subscription", subscriptionHandler.GetSubscription)
+	protected.Post("/subscription/identify", subscriptionHandler.Identify)
+
 	// Snap routes (protected)
 	protected.Post("/snaps", snapHandler.CreateSnap)
 	protected.Get("/snaps", snapHandler.GetMySnaps)
 	protected.Get("/snaps/streak", snapHandler.GetStreak)
 	protected.Get("/snaps/calendar", snapHandler.GetSnapCalendar)
-	protected.Post("/snaps/streak/freeze", snapHandler.AddFreeze)
+	protected.Get("/snaps/stats", statsHandler.GetStats)
+	protected.Post("/snaps/timelapse", timelapseHandler.CreateTimelapse)
+	protected.Get("/snaps/timelapse/:id", timelapseHandler.GetTimelapse)
+	protected.Get("/snaps/timelapse/:id/download", timelapseHandler.DownloadTimelapse)
+	protected.Get("/snaps/window", snapHandler.GetSnapWindow)
+	premium := middleware.RequireEntitlement(entitlements, entitlements.DefaultEntitlement())
+	protected.Post("/snaps/streak/freeze", premium, snapHandler.AddFreeze) // Free users earn or buy freezes
+	protected.Get("/snaps/streak/freezes", snapHandler.GetFreezeHistory)
+	protected.Get("/snaps/streak/history", snapHandler.GetStreakHistory)
+	protected.Post("/snaps/streak/cards", cardHandler.CreateCard)
+	protected.Get("/snaps/streak/cards", cardHandler.ListCards)
+	protected.Delete("/snaps/streak/cards/:id", cardHandler.RevokeCard)
 	protected.Delete("/snaps/:id", snapHandler.DeleteSnap)
 	protected.Post("/snaps/:id/like", snapHandler.LikeSnap)
 
+	// Daily prompts (protected)
+	protected.Get("/prompts/today", promptHandler.GetToday)
+	protected.Get("/prompts/:id/snaps", promptHandler.GetPromptSnaps)
+
+	// Notifications (protected)
+	protected.Get("/notifications", notificationHandler.ListNotifications)
+	protected.Post("/notifications/read", notificationHandler.MarkAllRead)
+
+	// Achievements (protected)
+	protected.Get("/achievements", achievementHandler.ListAchievements)
+
+	// Recaps (protected)
+	protected.Get("/recaps", recapHandler.ListRecaps)
+	protected.Get("/recaps/:id", recapHandler.GetRecap)
+	protected.Get("/recaps/:id/image", recapHandler.GetRecapImage)
+
 	// Moderation - User endpoints (protected)
 	protected.Post("/reports", moderationHandler.CreateReport)     // Report content (Guideline 1.2)
 	protected.Post("/blocks", moderationHandler.BlockUser)         // Block user (Guideline 1.2)
 	protected.Delete("/blocks/:id", moderationHandler.UnblockUser) // Unblock user
 
-	// Admin moderation panel (protected + admin check)
-	// In production, add an admin role middleware here
-	admin := api.Group("/admin", middleware.JWTProtected(cfg))
+	// Admin panel (protected + role check). Moderators review reports; everything registered
+	// after admin.Use below needs the admin role, so keep moderator routes above it.
+	admin := api.Group("/admin", middleware.JWTProtected(cfg), middleware.RequireRole(roles, models.RoleModerator))
 	admin.Get("/moderation/reports", moderationHandler.ListReports)
 	admin.Put("/moderation/reports/:id", moderationHandler.ActionReport)
 
-	// Webhooks (verified by auth header, not JWT)
+	admin.Use(middleware.RequireRole(roles, models.RoleAdmin))
+	admin.Put("/users/:id/role", roleHandler.SetRole)
+	admin.Post("/streaks/rebuild", snapHandler.RebuildAllStreaks)
+	admin.Post("/streaks/:userId/rebuild", snapHandler.RebuildStreak)
+	admin.Get("/prompts", promptHandler.ListPrompts)
+	admin.Post("/prompts", promptHandler.CreatePrompt)
+	admin.Put("/prompts/:id", promptHandler.UpdatePrompt)
+	admin.Delete("/prompts/:id", promptHandler.DeletePrompt)
+	admin.Get("/webhooks/events", webhookHandler.ListEvents)
+	admin.Post("/webhooks/events/:id/replay", webhookHandler.ReplayEvent)
+	admin.Post("/subscriptions/reconcile-orphans", subscriptionHandler.ReconcileOrphans)
+	admin.Post("/subscriptions/reconcile", subscriptionHandler.Reconcile)
+	admin.Post("/subscriptions/:id/reconcile", subscriptionHandler.ReconcileOne)
+	admin.Get("/subscriptions/corrections", subscriptionHandler.ListCorrections)
+	admin.Get("/analytics/revenue", analyticsHandler.GetRevenue)
+	admin.Post("/analytics/transactions/backfill", analyticsHandler.BackfillTransactions)
+	admin.Get("/audit-logs", auditHandler.ListAuditLogs)
+
+	// Webhooks (verified by shared secret or signature, not JWT)
 	webhooks := api.Group("/webhooks")
-	webhooks.Post("/revenuecat", webhookHandler.HandleRevenueCat)
+	webhooks.Post("/revenuecat", revenueCatWebhook, webhookHandler.HandleRevenueCat)
 }
@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobFunc is a unit of scheduled work. now is the tick time in UTC.
+type JobFunc func(ctx context.Context, now time.Time) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       JobFunc
+}
+
+// Scheduler runs periodic jobs. Each run first takes a DB lease named after the job,
+// held until the run finishes, so when several replicas are up only one of them executes a
+// given tick and runs never overlap.
+type Scheduler struct {
+	db     *gorm.DB
+	holder string
+	jobs   []job
+	wg     sync.WaitGroup
+}
+
+func New(db *gorm.DB) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		db:     db,
+		holder: fmt.Sprintf("%s/%d/%s", host, os.Getpid(), uuid.New().String()[:8]),
+	}
+}
+
+// Every registers fn to run once per interval.
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, fn: fn})
+}
+
+// Start launches all registered jobs. They stop when ctx is cancelled; use Wait to block until they have.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Wait blocks until every job loop has exited.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			s.run(ctx, j, t.UTC())
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j job, now time.Time) {
+	acquired, err := s.acquire(j.name, now, j.interval)
+	if err != nil {
+		log.Printf("scheduler: failed to acquire lease for %s: %v", j.name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	// Keep the lease alive while the job runs, so a run longer than the interval isn't
+	// started a second time by another replica. Losing the lease cancels the run.
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.heartbeat(runCtx, cancel, j)
+	}()
+
+	if err := j.fn(runCtx, now); err != nil {
+		log.Printf("scheduler: job %s failed: %v", j.name, err)
+	}
+	cancel()
+	<-done
+
+	// Hand the lease back with its original expiry; past ticks are free for any replica
+	if err := s.release(j.name, now.Add(j.interval)); err != nil {
+		log.Printf("scheduler: failed to release lease for %s: %v", j.name, err)
+	}
+}
+
+// heartbeat renews the job lease every third of the interval until ctx is cancelled.
+func (s *Scheduler) heartbeat(ctx context.Context, cancel context.CancelFunc, j job) {
+	ticker := time.NewTicker(j.interval / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			renewed, err := s.renew(j.name, t.UTC().Add(j.interval))
+			if err != nil {
+				// Transient; the lease still has at least two thirds of its TTL left
+				log.Printf("scheduler: failed to renew lease for %s: %v", j.name, err)
+				continue
+			}
+			if !renewed {
+				log.Printf("scheduler: lost lease for %s, cancelling run", j.name)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// acquire takes or renews the job lease. The conditional upsert is atomic in Postgres,
+// so two replicas racing for the same expired lease cannot both win.
+func (s *Scheduler) acquire(name string, now time.Time, ttl time.Duration) (bool, error) {
+	result := s.db.Exec(`
+		INSERT INTO job_leases (name, holder, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+		WHERE job_leases.expires_at <= ? OR job_leases.holder = ?`,
+		name, s.holder, now.Add(ttl), now, s.holder,
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// renew extends a lease this replica still holds. It reports false once another replica has taken it.
+func (s *Scheduler) renew(name string, expiresAt time.Time) (bool, error) {
+	result := s.db.Exec(`UPDATE job_leases SET expires_at = ? WHERE name = ? AND holder = ?`,
+		expiresAt, name, s.holder)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// release resets the expiry of a lease this replica holds once its run has finished.
+func (s *Scheduler) release(name string, expiresAt time.Time) error {
+	return s.db.Exec(`UPDATE job_leases SET expires_at = ? WHERE name = ? AND holder = ?`,
+		expiresAt, name, s.holder).Error
+}
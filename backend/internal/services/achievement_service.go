@@ -0,0 +1,134 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AchievementEvent is something a user did, with the running value that thresholds compare against.
+type AchievementEvent struct {
+	UserID uuid.UUID
+	Type   string
+	Value  int
+}
+
+// AchievementService evaluates events against achievement definitions and awards badges once.
+type AchievementService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+	definitions   []models.AchievementDefinition
+}
+
+// NewAchievementService loads definitions from definitionsFile (a JSON array) when set,
+// falling back to models.DefaultAchievements.
+func NewAchievementService(db *gorm.DB, notifications *NotificationService, definitionsFile string) *AchievementService {
+	definitions := models.DefaultAchievements
+	if definitionsFile != "" {
+		loaded, err := loadAchievementDefinitions(definitionsFile)
+		if err != nil {
+			log.Printf("Warning: Could not load achievements from %s, using defaults: %v", definitionsFile, err)
+		} else {
+			definitions = loaded
+		}
+	}
+	return &AchievementService{db: db, notifications: notifications, definitions: definitions}
+}
+
+func loadAchievementDefinitions(path string) ([]models.AchievementDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var definitions []models.AchievementDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, err
+	}
+	for _, d := range definitions {
+		if d.Key == "" || d.Event == "" || d.Threshold < 1 {
+			return nil, fmt.Errorf("invalid achievement definition %q", d.Key)
+		}
+		if !slices.Contains(models.AchievementEvents, d.Event) {
+			return nil, fmt.Errorf("achievement %q uses unknown event %q", d.Key, d.Event)
+		}
+	}
+	return definitions, nil
+}
+
+// Evaluate awards every achievement whose threshold the events reached and that the user
+// doesn't have yet, notifying the user for each new badge. Failures are logged, never returned,
+// so callers can fire events after their own work has committed.
+func (s *AchievementService) Evaluate(events ...AchievementEvent) {
+	if s == nil {
+		return
+	}
+	for _, event := range events {
+		for _, def := range s.definitions {
+			if def.Event != event.Type || event.Value < def.Threshold {
+				continue
+			}
+			if err := s.award(event.UserID, def); err != nil {
+				log.Printf("warning: failed to award achievement %s to user %s: %v", def.Key, event.UserID, err)
+			}
+		}
+	}
+}
+
+func (s *AchievementService) award(userID uuid.UUID, def models.AchievementDefinition) error {
+	earned := models.UserAchievement{
+		ID:             uuid.New(),
+		UserID:         userID,
+		AchievementKey: def.Key,
+		EarnedAt:       time.Now(),
+	}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&earned)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	if s.notifications != nil {
+		title := fmt.Sprintf("%s Achievement unlocked: %s", def.Icon, def.Title)
+		if _, err := s.notifications.Notify(userID, models.NotificationAchievement, title, def.Description); err != nil {
+			log.Printf("warning: failed to notify achievement %s to user %s: %v", def.Key, userID, err)
+		}
+	}
+	return nil
+}
+
+// List returns every achievement with the user's earned status, in definition order.
+func (s *AchievementService) List(userID uuid.UUID) ([]dto.AchievementResponse, error) {
+	var earned []models.UserAchievement
+	if err := s.db.Where("user_id = ?", userID).Find(&earned).Error; err != nil {
+		return nil, fmt.Errorf("failed to load achievements: %w", err)
+	}
+	earnedAt := make(map[string]time.Time, len(earned))
+	for _, e := range earned {
+		earnedAt[e.AchievementKey] = e.EarnedAt
+	}
+
+	list := make([]dto.AchievementResponse, len(s.definitions))
+	for i, def := range s.definitions {
+		list[i] = dto.AchievementResponse{
+			Key:         def.Key,
+			Title:       def.Title,
+			Description: def.Description,
+			Icon:        def.Icon,
+			Threshold:   def.Threshold,
+		}
+		if at, ok := earnedAt[def.Key]; ok {
+			t := at
+			list[i].Earned = true
+			list[i].EarnedAt = &t
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"gorm.io/gorm"
+)
+
+const (
+	analyticsDefaultDays = 30
+	secondsPerMonth      = 30.4375 * 24 * 60 * 60 // average month, for normalizing billing periods to MRR
+)
+
+// AnalyticsService reports revenue and subscriber metrics from the recorded subscription transactions.
+type AnalyticsService struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	return &AnalyticsService{db: db}
+}
+
+// paidAt selects, per subscription (original transaction), the latest paid, unrefunded billing
+// period covering ?. Its arguments are environment, then the time three times.
+const paidAt = `
+	SELECT DISTINCT ON (t.original_transaction_id) t.original_transaction_id, t.price, t.purchased_at, t.expires_at
+	FROM subscription_transactions t
+	WHERE t.environment = ? AND t.price > 0 AND t.period_type <> 'TRIAL' AND t.expires_at IS NOT NULL
+		AND t.purchased_at <= ? AND t.expires_at > ?
+		AND NOT EXISTS (
+			SELECT 1 FROM subscription_transactions r
+			WHERE r.original_transaction_id = t.original_transaction_id AND r.price < 0 AND r.occurred_at <= ?
+		)
+	ORDER BY t.original_transaction_id, t.purchased_at DESC`
+
+// RevenueAnalytics reports revenue, MRR, active subscribers, trial conversion and churn for the
+// UTC days from..to (YYYY-MM-DD, default the last 30 days). Environment defaults to PRODUCTION.
+func (s *AnalyticsService) RevenueAnalytics(from, to, environment string) (*dto.RevenueAnalyticsResponse, error) {
+	end := startOfDay(time.Now().UTC(), time.UTC)
+	if to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return nil, ErrInvalidDateRange
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, -(analyticsDefaultDays - 1))
+	if from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return nil, ErrInvalidDateRange
+		}
+		start = t
+	}
+	if start.After(end) {
+		return nil, ErrInvalidDateRange
+	}
+	environment = strings.ToUpper(strings.TrimSpace(environment))
+	if environment == "" {
+		environment = "PRODUCTION"
+	}
+	until := end.AddDate(0, 0, 1) // exclusive
+
+	resp := &dto.RevenueAnalyticsResponse{
+		From:        start.Format("2006-01-02"),
+		To:          end.Format("2006-01-02"),
+		Environment: environment,
+		Currency:    "USD",
+		ByCountry:   []dto.RevenueBreakdown{},
+		ByProduct:   []dto.RevenueBreakdown{},
+	}
+
+	var totals struct {
+		Revenue      float64
+		Refunds      float64
+		Transactions int64
+	}
+	err := s.db.Raw(`
+		SELECT COALESCE(SUM(price) FILTER (WHERE price > 0), 0) AS revenue,
+			COALESCE(-SUM(price) FILTER (WHERE price < 0), 0) AS refunds,
+			COUNT(*) FILTER (WHERE price > 0) AS transactions
+		FROM subscription_transactions
+		WHERE environment = ? AND occurred_at >= ? AND occurred_at < ?`,
+		environment, start, until).Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to total revenue: %w", err)
+	}
+	resp.Revenue = roundMoney(totals.Revenue)
+	resp.Refunds = roundMoney(totals.Refunds)
+	resp.NetRevenue = roundMoney(totals.Revenue - totals.Refunds)
+	resp.Transactions = totals.Transactions
+
+	for column, target := range map[string]*[]dto.RevenueBreakdown{"country_code": &resp.ByCountry, "product_id": &resp.ByProduct} {
+		var rows []dto.RevenueBreakdown
+		err := s.db.Raw(`
+			SELECT COALESCE(NULLIF(`+column+`, ''), 'unknown') AS key,
+				SUM(price) AS revenue, COUNT(*) FILTER (WHERE price > 0) AS transactions
+			FROM subscription_transactions
+			WHERE environment = ? AND occurred_at >= ? AND occurred_at < ? AND price <> 0
+			GROUP BY 1
+			ORDER BY revenue DESC, key`,
+			environment, start, until).Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to break down revenue by %s: %w", column, err)
+		}
+		for i := range rows {
+			rows[i].Revenue = roundMoney(rows[i].Revenue)
+		}
+		if rows != nil {
+			*target = rows
+		}
+	}
+
+	var current struct {
+		MRR         float64
+		Subscribers int64
+	}
+	err = s.db.Raw(`
+		SELECT COALESCE(SUM(p.price * ? / GREATEST(EXTRACT(EPOCH FROM p.expires_at - p.purchased_at), 1)), 0) AS mrr,
+			COUNT(*) AS subscribers
+		FROM (`+paidAt+`) p`,
+		secondsPerMonth, environment, until, until, until).Scan(&current).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute MRR: %w", err)
+	}
+	resp.MRR = roundMoney(current.MRR)
+	resp.ActiveSubscribers = current.Subscribers
+
+	err = s.db.Raw(`
+		SELECT COUNT(DISTINCT t.original_transaction_id)
+		FROM subscription_transactions t
+		WHERE t.environment = ? AND t.period_type = 'TRIAL' AND t.purchased_at <= ? AND t.expires_at > ?
+			AND t.original_transaction_id NOT IN (SELECT p.original_transaction_id FROM (`+paidAt+`) p)`,
+		environment, until, until, environment, until, until, until).Scan(&resp.ActiveTrials).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count trials: %w", err)
+	}
+
+	// Trials started in the range and how many of them have converted since
+	var trials struct {
+		Started   int64
+		Converted int64
+	}
+	err = s.db.Raw(`
+		SELECT COUNT(*) AS started,
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM subscription_transactions c
+				WHERE c.original_transaction_id = t.original_transaction_id AND c.is_trial_conversion
+			)) AS converted
+		FROM (
+			SELECT DISTINCT original_transaction_id FROM subscription_transactions
+			WHERE environment = ? AND period_type = 'TRIAL' AND event_type = 'INITIAL_PURCHASE'
+				AND occurred_at >= ? AND occurred_at < ?
+		) t`,
+		environment, start, until).Scan(&trials).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute trial conversion: %w", err)
+	}
+	resp.TrialsStarted = trials.Started
+	resp.TrialsConverted = trials.Converted
+	resp.TrialConversionRate = ratio(trials.Converted, trials.Started)
+
+	// Churn: paying subscribers at the start of the range who no longer pay at its end
+	var churn struct {
+		AtStart int64
+		Churned int64
+	}
+	err = s.db.Raw(`
+		SELECT COUNT(*) AS at_start,
+			COUNT(*) FILTER (WHERE s.original_transaction_id NOT IN (SELECT e.original_transaction_id FROM (`+paidAt+`) e)) AS churned
+		FROM (`+paidAt+`) s`,
+		environment, until, until, until, environment, start, start, start).Scan(&churn).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute churn: %w", err)
+	}
+	resp.SubscribersAtStart = churn.AtStart
+	resp.ChurnedSubscribers = churn.Churned
+	resp.ChurnRate = ratio(churn.Churned, churn.AtStart)
+
+	return resp, nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ratio returns part/whole rounded to four decimals, or 0 when whole is 0.
+func ratio(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
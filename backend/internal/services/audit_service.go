@@ -0,0 +1,179 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditEntry describes one audited action. Before and After are stored as JSON; services that
+// take an *AuditEntry fill them in from the change they make, and nil means don't audit.
+type AuditEntry struct {
+	ActorID    *uuid.UUID
+	ActorRole  string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     interface{}
+	After      interface{}
+	RequestID  string
+	IP         string
+}
+
+// AuditService appends to and searches the audit log. There is deliberately no way to change
+// or remove entries.
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record appends an entry to the audit log. Use it for actions that span several transactions;
+// a change made in one transaction should pass its entry to the service so it is written by
+// that same transaction.
+func (s *AuditService) Record(entry AuditEntry) error {
+	return entry.record(s.db, entry.Before, entry.After)
+}
+
+// record writes the entry with the given before and after values using db, which is the
+// transaction making the audited change, so the change and its entry commit or roll back
+// together. A nil entry records nothing.
+func (e *AuditEntry) record(db *gorm.DB, before, after interface{}) error {
+	if e == nil {
+		return nil
+	}
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	record := models.AuditLog{
+		ID:         uuid.New(),
+		ActorID:    e.ActorID,
+		ActorRole:  e.ActorRole,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		RequestID:  e.RequestID,
+		IP:         e.IP,
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func auditJSON(v interface{}) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit values: %w", err)
+	}
+	s := string(data)
+	return &s, nil
+}
+
+// List returns audit entries matching the filter, newest first.
+func (s *AuditService) List(filter dto.AuditLogFilter, limit, offset int) ([]dto.AuditLogResponse, int64, error) {
+	query := s.db.Model(&models.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var records []models.AuditLog
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]dto.AuditLogResponse, len(records))
+	for i, r := range records {
+		entries[i] = dto.AuditLogResponse{
+			ID:         r.ID,
+			ActorID:    r.ActorID,
+			ActorRole:  r.ActorRole,
+			Action:     r.Action,
+			TargetType: r.TargetType,
+			TargetID:   r.TargetID,
+			RequestID:  r.RequestID,
+			IP:         r.IP,
+			CreatedAt:  r.CreatedAt,
+		}
+		if r.Before != nil {
+			entries[i].Before = json.RawMessage(*r.Before)
+		}
+		if r.After != nil {
+			entries[i].After = json.RawMessage(*r.After)
+		}
+	}
+	return entries, total, nil
+}
+
+// auditDay parses a YYYY-MM-DD filter bound as the start of that UTC day.
+func auditDay(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, ErrInvalidDateRange
+	}
+	return &t, nil
+}
+
+// ParseAuditRange turns from/to query values (YYYY-MM-DD, inclusive) into filter bounds.
+func ParseAuditRange(from, to string) (*time.Time, *time.Time, error) {
+	start, err := auditDay(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := auditDay(to)
+	if err != nil {
+		return nil, nil, err
+	}
+	if end != nil {
+		next := end.AddDate(0, 0, 1)
+		end = &next
+	}
+	if start != nil && end != nil && !start.Before(*end) {
+		return nil, nil, ErrInvalidDateRange
+	}
+	return start, end, nil
+}
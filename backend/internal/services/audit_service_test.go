@@ -0,0 +1,60 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestSetRoleAuditedInTransaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		entry       *AuditEntry
+		wantErr     bool
+		wantRole    string
+		wantEntries int64
+	}{
+		{
+			name:        "entry is written with the change",
+			entry:       &AuditEntry{Action: models.AuditRoleChanged, TargetType: "user"},
+			wantRole:    models.RoleModerator,
+			wantEntries: 1,
+		},
+		{
+			name: "failed audit write rolls the change back",
+			// Longer than the actor_role column, so the insert fails
+			entry:    &AuditEntry{Action: models.AuditRoleChanged, TargetType: "user", ActorRole: strings.Repeat("x", 21)},
+			wantErr:  true,
+			wantRole: models.RoleUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			userID := uuid.New()
+			createTestUser(t, db, userID)
+
+			_, _, err := NewRoleService(db).SetRole(userID, models.RoleModerator, tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetRole: err = %v, want error %v", err, tt.wantErr)
+			}
+
+			var user models.User
+			if err := db.First(&user, "id = ?", userID).Error; err != nil {
+				t.Fatalf("load user: %v", err)
+			}
+			if user.Role != tt.wantRole {
+				t.Errorf("role = %q, want %q", user.Role, tt.wantRole)
+			}
+
+			var entries int64
+			db.Model(&models.AuditLog{}).Where("action = ?", models.AuditRoleChanged).Count(&entries)
+			if entries != tt.wantEntries {
+				t.Errorf("audit entries = %d, want %d", entries, tt.wantEntries)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrInvalidToken       = errors.New("invalid or expired refresh token")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidTimezone    = errors.New("invalid timezone")
 )
 
 type AuthService struct {
@@ -54,6 +55,7 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 		ID:       uuid.New(),
 		Email:    req.Email,
 		Password: string(hash),
+		Role:     models.RoleUser,
 	}
 
 	if err := s.db.Create(&user).Error; err != nil {
@@ -109,10 +111,39 @@ func (s *AuthService) GetProfile(userID uuid.UUID) (*dto.ProfileResponse, error)
 	return &dto.ProfileResponse{
 		ID:        user.ID.String(),
 		Email:     user.Email,
+		Role:      user.Role,
+		Timezone:  user.Timezone,
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 	}, nil
 }
 
+// UpdateProfile changes the user's time zone and/or push token.
+func (s *AuthService) UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequest) (*dto.ProfileResponse, error) {
+	updates := map[string]interface{}{}
+
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return nil, ErrInvalidTimezone
+		}
+		updates["timezone"] = *req.Timezone
+	}
+	if req.PushToken != nil {
+		updates["push_token"] = *req.PushToken
+	}
+
+	if len(updates) > 0 {
+		result := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to update profile: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	return s.GetProfile(userID)
+}
+
 func (s *AuthService) Logout(req *dto.LogoutRequest) error {
 	tokenHash := hashToken(req.RefreshToken)
 	return s.db.Model(&models.RefreshToken{}).
@@ -122,7 +153,8 @@ func (s *AuthService) Logout(req *dto.LogoutRequest) error {
 
 // DeleteAccount implements Apple Guideline 5.1.1(v) - account deletion.
 // Scrubs all user data: tokens, subscriptions, reports, blocks, then soft-deletes user.
-func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) error {
+// The deletion is audited with audit.
+func (s *AuthService) DeleteAccount(userID uuid.UUID, password string, audit *AuditEntry) error {
 	var user models.User
 	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
 		return ErrUserNotFound
@@ -142,6 +174,11 @@ func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) error {
 
 		// Remove subscriptions
 		tx.Where("user_id = ?", userID).Delete(&models.Subscription{})
+		tx.Where("user_id = ?", userID).Delete(&models.RevenueCatAlias{})
+
+		// Keep revenue records for accounting, detached from the user
+		tx.Model(&models.SubscriptionTransaction{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"user_id": nil, "revenuecat_id": ""})
 
 		// Remove reports filed by user
 		tx.Where("reporter_id = ?", userID).Delete(&models.Report{})
@@ -152,9 +189,25 @@ func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) error {
 		// Remove snap data (Snapstreak-specific cleanup)
 		tx.Where("user_id = ?", userID).Delete(&models.Snap{})
 		tx.Where("user_id = ?", userID).Delete(&models.SnapStreak{})
+		tx.Where("user_id = ?", userID).Delete(&models.FreezeTransaction{})
+		tx.Where("user_id = ?", userID).Delete(&models.FrozenDay{})
+		tx.Where("user_id = ?", userID).Delete(&models.StreakEpoch{})
+		tx.Where("user_id = ?", userID).Delete(&models.UserAchievement{})
+		tx.Where("user_id = ?", userID).Delete(&models.MilestoneCard{})
+		tx.Where("user_id = ?", userID).Delete(&models.Recap{})
+		tx.Where("user_id = ?", userID).Delete(&models.TimelapseJob{})
+
+		// Remove notifications and reminder bookkeeping
+		tx.Where("user_id = ?", userID).Delete(&models.Notification{})
+		tx.Where("user_id = ?", userID).Delete(&models.NotificationReceipt{})
 
 		// Soft-delete the user (GORM DeletedAt)
-		return tx.Delete(&user).Error
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+
+		// No personal data in the entry; the account it refers to no longer exists
+		return audit.record(tx, nil, nil)
 	})
 }
 
@@ -208,6 +261,7 @@ func (s *AuthService) AppleSignIn(req *dto.AppleSignInRequest) (*dto.AuthRespons
 			ID:       uuid.New(),
 			Email:    email,
 			Password: "", // Apple users have no password
+			Role:     models.RoleUser,
 		}
 		if err := s.db.Create(&user).Error; err != nil {
 			return nil, fmt.Errorf("failed to create Apple user: %w", err)
@@ -242,6 +296,7 @@ func (s *AuthService) generateAccessToken(user *models.User) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":   user.ID.String(),
 		"email": user.Email,
+		"role":  user.Role,
 		"iat":   time.Now().Unix(),
 		"exp":   time.Now().Add(s.cfg.JWTAccessExpiry).Unix(),
 	}
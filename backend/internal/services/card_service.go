@@ -0,0 +1,209 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Milestone card layout (Instagram Stories / TikTok portrait).
+const (
+	CardWidth     = 1080
+	CardHeight    = 1920
+	cardGridSize  = 3
+	cardThumbSize = 300
+	cardThumbGap  = 30
+)
+
+var (
+	ErrCardNotFound        = errors.New("card not found")
+	ErrMilestoneNotReached = errors.New("milestone not reached")
+)
+
+// CardService renders shareable streak milestone cards and manages their share links.
+type CardService struct {
+	db  *gorm.DB
+	dir string
+}
+
+// NewCardService stores rendered cards in dir, which must not be publicly served:
+// cards are only reachable through their (revocable) share token.
+func NewCardService(db *gorm.DB, dir string) *CardService {
+	return &CardService{db: db, dir: dir}
+}
+
+// CreateCard returns the active card for a milestone the user has reached, rendering it on first use.
+// A milestone of 0 means the current streak.
+func (s *CardService) CreateCard(userID uuid.UUID, milestone int) (*models.MilestoneCard, error) {
+	var streak models.SnapStreak
+	if err := s.db.Where("user_id = ?", userID).First(&streak).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMilestoneNotReached
+		}
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+	if milestone == 0 {
+		milestone = streak.CurrentStreak
+	}
+	if milestone < 1 || milestone > streak.LongestStreak {
+		return nil, ErrMilestoneNotReached
+	}
+
+	var card models.MilestoneCard
+	err := s.db.Where("user_id = ? AND milestone = ? AND revoked_at IS NULL", userID, milestone).First(&card).Error
+	if err == nil {
+		if _, statErr := os.Stat(card.ImagePath); statErr != nil {
+			if err := s.render(&card); err != nil {
+				return nil, err
+			}
+		}
+		return &card, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	card = models.MilestoneCard{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Milestone: milestone,
+		Token:     token,
+	}
+	card.ImagePath = filepath.Join(s.dir, card.ID.String()+".png")
+	if err := s.render(&card); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&card).Error; err != nil {
+		os.Remove(card.ImagePath)
+		// A concurrent request created the card first; share that one
+		if isUniqueViolation(err, "idx_milestone_card_active") {
+			var existing models.MilestoneCard
+			if err := s.db.Where("user_id = ? AND milestone = ? AND revoked_at IS NULL", userID, milestone).
+				First(&existing).Error; err == nil {
+				return &existing, nil
+			}
+		}
+		return nil, fmt.Errorf("failed to save card: %w", err)
+	}
+	return &card, nil
+}
+
+// ListCards returns the user's active cards, newest first.
+func (s *CardService) ListCards(userID uuid.UUID) ([]models.MilestoneCard, error) {
+	var cards []models.MilestoneCard
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&cards).Error
+	return cards, err
+}
+
+// GetSharedCard resolves a public share token to the card's image file, re-rendering it if the file is gone.
+func (s *CardService) GetSharedCard(token string) (string, error) {
+	var card models.MilestoneCard
+	if err := s.db.Where("token = ? AND revoked_at IS NULL", token).First(&card).Error; err != nil {
+		return "", ErrCardNotFound
+	}
+	if _, err := os.Stat(card.ImagePath); err != nil {
+		if err := s.render(&card); err != nil {
+			return "", err
+		}
+	}
+	return card.ImagePath, nil
+}
+
+// RevokeCard disables a card's share link and deletes its image. Sharing the milestone
+// again creates a new card with a new link.
+func (s *CardService) RevokeCard(userID, cardID uuid.UUID) error {
+	var card models.MilestoneCard
+	if err := s.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", cardID, userID).First(&card).Error; err != nil {
+		return ErrCardNotFound
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&card).Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke card: %w", err)
+	}
+	os.Remove(card.ImagePath)
+	return nil
+}
+
+// render draws the card (branding, streak count and a grid of the latest snaps) to card.ImagePath.
+func (s *CardService) render(card *models.MilestoneCard) error {
+	var snaps []models.Snap
+	if err := s.db.Where("user_id = ?", card.UserID).
+		Order("snap_date DESC").
+		Limit(cardGridSize * cardGridSize).
+		Find(&snaps).Error; err != nil {
+		return fmt.Errorf("failed to load snaps: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	fillGradient(img, brandOrange, brandPink)
+
+	drawCenteredText(img, "STREAKSNAP", 120, 8, color.White)
+	count, scale := strconv.Itoa(card.Milestone), 40
+	for scale > 8 && textWidth(count, scale) > CardWidth-120 {
+		scale -= 4
+	}
+	drawCenteredText(img, count, 260+(40-scale)*7/2, scale, color.White)
+	label := "DAY STREAK"
+	if card.Milestone == 1 {
+		label = "DAY ONE"
+	}
+	drawCenteredText(img, label, 600, 12, color.White)
+
+	gridWidth := cardGridSize*cardThumbSize + (cardGridSize-1)*cardThumbGap
+	left, top := (CardWidth-gridWidth)/2, 780
+	for i := 0; i < cardGridSize*cardGridSize; i++ {
+		x := left + (i%cardGridSize)*(cardThumbSize+cardThumbGap)
+		y := top + (i/cardGridSize)*(cardThumbSize+cardThumbGap)
+
+		var thumb image.Image
+		if i < len(snaps) {
+			thumb, _ = loadSnapImage(snaps[i].ImageURL) // undecodable images (e.g. HEIC) get a placeholder
+		}
+		drawThumbnail(img, image.Rect(x, y, x+cardThumbSize, y+cardThumbSize), thumb)
+	}
+
+	drawCenteredText(img, "ONE SNAP A DAY", 1800, 6, color.White)
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create card directory: %w", err)
+	}
+	f, err := os.Create(card.ImagePath)
+	if err != nil {
+		return fmt.Errorf("failed to write card: %w", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("failed to encode card: %w", err)
+	}
+	return nil
+}
+
+// newShareToken returns a random URL-safe token for public share links.
+func newShareToken() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAppUserIDTaken       = errors.New("this RevenueCat ID belongs to another account")
+	ErrInvalidAppUserID     = errors.New("RevenueCat IDs must be your user ID or an anonymous RevenueCat ID")
+	ErrAppUserIDNotVerified = errors.New("RevenueCat does not link this ID to your account")
+)
+
+// anonymousAppUserIDPrefix marks the IDs the RevenueCat SDK generates before login.
+const anonymousAppUserIDPrefix = "$RCAnonymousID:"
+
+// eventAppUserIDs returns every RevenueCat ID the event refers to the customer by.
+func eventAppUserIDs(event *dto.RevenueCatEvent) []string {
+	ids := []string{event.AppUserID}
+	for _, id := range append([]string{event.OriginalAppUserID}, event.Aliases...) {
+		if id != "" && id != event.AppUserID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// resolveUser finds the user behind any of the app user IDs: first by an ID that is itself one
+// of our user IDs (the SDK is configured with the user ID), then through registered aliases. User
+// IDs win so an alias can never redirect another user's purchases.
+func (s *SubscriptionService) resolveUser(appUserIDs ...string) (uuid.UUID, bool) {
+	for _, id := range appUserIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			continue
+		}
+		var user models.User
+		if err := s.db.Select("id").Where("id = ?", id).First(&user).Error; err == nil {
+			return user.ID, true
+		}
+	}
+
+	var alias models.RevenueCatAlias
+	if err := s.db.Where("app_user_id IN ?", appUserIDs).First(&alias).Error; err == nil {
+		return alias.UserID, true
+	}
+	return uuid.Nil, false
+}
+
+// registerAliases maps the app user IDs to the user and attaches any orphaned subscriptions and
+// consumable purchases recorded under them. IDs already mapped (to this or another user) are left
+// as they are.
+func (s *SubscriptionService) registerAliases(db *gorm.DB, userID uuid.UUID, appUserIDs []string) (int64, error) {
+	for _, id := range appUserIDs {
+		if id == "" {
+			continue
+		}
+		alias := models.RevenueCatAlias{ID: uuid.New(), AppUserID: id, UserID: userID}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alias).Error; err != nil {
+			return 0, fmt.Errorf("failed to register alias: %w", err)
+		}
+	}
+
+	if _, err := s.creditOrphanedPurchases(db, userID, appUserIDs); err != nil {
+		return 0, err
+	}
+
+	result := db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ? AND user_id IS NULL", appUserIDs).
+		Update("user_id", userID)
+	return result.RowsAffected, result.Error
+}
+
+// creditOrphanedPurchases attaches non-renewing purchases stored without a user to the user and
+// credits the freezes they bought. It returns how many purchases were attached.
+func (s *SubscriptionService) creditOrphanedPurchases(db *gorm.DB, userID uuid.UUID, appUserIDs []string) (int, error) {
+	var purchases []models.SubscriptionTransaction
+	if err := db.Where("event_type = ? AND user_id IS NULL AND revenuecat_id IN ?", "NON_RENEWING_PURCHASE", appUserIDs).
+		Find(&purchases).Error; err != nil {
+		return 0, fmt.Errorf("failed to load orphaned purchases: %w", err)
+	}
+
+	for _, purchase := range purchases {
+		if err := db.Model(&purchase).Update("user_id", userID).Error; err != nil {
+			return 0, fmt.Errorf("failed to link purchase: %w", err)
+		}
+		if s.freezes == nil {
+			continue
+		}
+		if _, err := s.freezes.creditPurchase(db, userID, purchase.ProductID, purchaseReference(purchase.TransactionID, purchase.EventID)); err != nil {
+			return 0, fmt.Errorf("failed to credit purchase %s: %w", purchase.EventID, err)
+		}
+	}
+	return len(purchases), nil
+}
+
+// linkIdentities records the event's app user IDs as aliases of the user they resolve to.
+func (s *SubscriptionService) linkIdentities(event *dto.RevenueCatEvent) error {
+	ids := eventAppUserIDs(event)
+	userID, ok := s.resolveUser(ids...)
+	if !ok {
+		return nil
+	}
+	_, err := s.registerAliases(s.db, userID, ids)
+	return err
+}
+
+// Identify registers the RevenueCat app user ID (and any aliases) the client is using for the
+// signed-in user, typically right after login, and attaches subscriptions bought under them.
+// Only the user's own ID and anonymous SDK IDs are accepted, and when the RevenueCat API is
+// configured anonymous IDs must belong to the same RevenueCat customer as the user. The
+// registration is audited with audit.
+func (s *SubscriptionService) Identify(ctx context.Context, userID uuid.UUID, appUserID string, aliases []string, audit *AuditEntry) (int64, error) {
+	ids := append([]string{appUserID}, aliases...)
+
+	var anonymous []string
+	for _, id := range ids {
+		switch {
+		case id == userID.String():
+		case strings.HasPrefix(id, anonymousAppUserIDPrefix) && len(id) > len(anonymousAppUserIDPrefix):
+			anonymous = append(anonymous, id)
+		default:
+			return 0, ErrInvalidAppUserID
+		}
+	}
+	if err := s.verifyAliases(ctx, userID, anonymous); err != nil {
+		return 0, err
+	}
+
+	var taken int64
+	if err := s.db.Model(&models.RevenueCatAlias{}).
+		Where("app_user_id IN ? AND user_id <> ?", ids, userID).
+		Count(&taken).Error; err != nil {
+		return 0, err
+	}
+	if taken > 0 {
+		return 0, ErrAppUserIDTaken
+	}
+
+	var linked int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		linked, err = s.registerAliases(tx, userID, ids)
+		if err != nil {
+			return err
+		}
+		return audit.record(tx, nil, map[string]interface{}{
+			"app_user_id":          appUserID,
+			"aliases":              aliases,
+			"linked_subscriptions": linked,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	if s.entitlements != nil {
+		s.entitlements.Invalidate(userID)
+	}
+	return linked, nil
+}
+
+// ReconcileOrphanedSubscriptions attaches subscriptions and freeze purchases stored without a
+// user to the user their RevenueCat ID now resolves to. It returns how many subscriptions were
+// linked and how many remain orphaned, plus how many purchases were credited.
+func (s *SubscriptionService) ReconcileOrphanedSubscriptions() (*dto.ReconcileOrphansResponse, error) {
+	var orphans []models.Subscription
+	if err := s.db.Where("user_id IS NULL").Find(&orphans).Error; err != nil {
+		return nil, fmt.Errorf("failed to load orphaned subscriptions: %w", err)
+	}
+
+	report := &dto.ReconcileOrphansResponse{Checked: len(orphans)}
+	for _, sub := range orphans {
+		userID, ok := s.resolveUser(sub.RevenueCatID)
+		if !ok {
+			report.Remaining++
+			continue
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			_, err := s.registerAliases(tx, userID, []string{sub.RevenueCatID})
+			return err
+		})
+		if err != nil {
+			log.Printf("warning: failed to link subscription %s to user %s: %v", sub.ID, userID, err)
+			report.Remaining++
+			continue
+		}
+		report.Linked++
+		if s.entitlements != nil {
+			s.entitlements.Invalidate(userID)
+		}
+	}
+
+	// Purchases under IDs that never had a subscription
+	var purchaseIDs []string
+	if err := s.db.Model(&models.SubscriptionTransaction{}).
+		Where("event_type = ? AND user_id IS NULL AND revenuecat_id <> ''", "NON_RENEWING_PURCHASE").
+		Distinct().Pluck("revenuecat_id", &purchaseIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load orphaned purchases: %w", err)
+	}
+	for _, appUserID := range purchaseIDs {
+		userID, ok := s.resolveUser(appUserID)
+		if !ok {
+			continue
+		}
+		var credited int
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			credited, err = s.creditOrphanedPurchases(tx, userID, []string{appUserID})
+			return err
+		})
+		if err != nil {
+			log.Printf("warning: failed to credit purchases of %q to user %s: %v", appUserID, userID, err)
+			continue
+		}
+		report.PurchasesLinked += credited
+	}
+	return report, nil
+}
+
+// verifyAliases checks with RevenueCat that each anonymous ID resolves to the same customer as
+// the user's own ID. Without an API key there is nothing to check against, and the IDs are
+// accepted since anonymous IDs are random and never exposed by this API.
+func (s *SubscriptionService) verifyAliases(ctx context.Context, userID uuid.UUID, anonymous []string) error {
+	if len(anonymous) == 0 || !s.revenueCat.Enabled() {
+		return nil
+	}
+
+	own, err := s.revenueCat.GetSubscriber(ctx, userID.String())
+	if err != nil {
+		if errors.Is(err, ErrRevenueCatNotFound) {
+			return ErrAppUserIDNotVerified
+		}
+		return err
+	}
+	for _, id := range anonymous {
+		subscriber, err := s.revenueCat.GetSubscriber(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrRevenueCatNotFound) {
+				return ErrAppUserIDNotVerified
+			}
+			return err
+		}
+		if subscriber.OriginalAppUserID != own.OriginalAppUserID {
+			return ErrAppUserIDNotVerified
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// entitlementCacheTTL bounds how stale another replica's cache can be after a webhook.
+const entitlementCacheTTL = 5 * time.Minute
+
+// EntitlementService resolves which RevenueCat entitlements a user currently has.
+// Results are cached per user and invalidated when webhooks change their subscriptions.
+type EntitlementService struct {
+	db                 *gorm.DB
+	defaultEntitlement string
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]entitlementCacheEntry
+}
+
+type entitlementCacheEntry struct {
+	entitlements map[string]*time.Time // entitlement -> entitled until, nil for never
+	expiresAt    time.Time
+}
+
+// NewEntitlementService grants defaultEntitlement for subscriptions recorded without entitlement IDs.
+func NewEntitlementService(db *gorm.DB, defaultEntitlement string) *EntitlementService {
+	return &EntitlementService{
+		db:                 db,
+		defaultEntitlement: defaultEntitlement,
+		cache:              make(map[uuid.UUID]entitlementCacheEntry),
+	}
+}
+
+// DefaultEntitlement is the entitlement premium features are gated on.
+func (s *EntitlementService) DefaultEntitlement() string {
+	return s.defaultEntitlement
+}
+
+// entitledUntil reports whether a subscription grants access at now, and until when: the end of
+// the paid period, extended by the grace period while the store retries a failed charge. A nil
+// end means the access never ends (lifetime purchases). Paused and expired subscriptions grant
+// nothing.
+func entitledUntil(sub *models.Subscription, now time.Time) (*time.Time, bool) {
+	until := sub.CurrentPeriodEnd
+	switch sub.Status {
+	case models.SubscriptionActive, models.SubscriptionCancelled:
+	case models.SubscriptionGracePeriod:
+		if until != nil && sub.GracePeriodExpiresAt != nil && sub.GracePeriodExpiresAt.After(*until) {
+			until = sub.GracePeriodExpiresAt
+		}
+	default:
+		return nil, false
+	}
+	return until, until == nil || until.After(now)
+}
+
+// Entitlements returns the user's active entitlements and when each one ends (nil if it never
+// does).
+func (s *EntitlementService) Entitlements(userID uuid.UUID) (map[string]*time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.entitlements, nil
+	}
+
+	var subs []models.Subscription
+	if err := s.db.Where("user_id = ?", userID).Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+
+	entitlements := make(map[string]*time.Time)
+	expiresAt := now.Add(entitlementCacheTTL)
+	for i := range subs {
+		until, ok := entitledUntil(&subs[i], now)
+		if !ok {
+			continue
+		}
+		ids := subs[i].Entitlements()
+		if len(ids) == 0 {
+			ids = []string{s.defaultEntitlement}
+		}
+		for _, id := range ids {
+			current, seen := entitlements[id]
+			if !seen || (current != nil && (until == nil || until.After(*current))) {
+				entitlements[id] = until
+			}
+		}
+		// Don't serve an entitlement from cache after it has lapsed
+		if until != nil && until.Before(expiresAt) {
+			expiresAt = *until
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = entitlementCacheEntry{entitlements: entitlements, expiresAt: expiresAt}
+	s.mu.Unlock()
+	return entitlements, nil
+}
+
+// Has reports whether the user currently has the entitlement.
+func (s *EntitlementService) Has(userID uuid.UUID, entitlement string) (bool, error) {
+	entitlements, err := s.Entitlements(userID)
+	if err != nil {
+		return false, err
+	}
+	_, ok := entitlements[entitlement]
+	return ok, nil
+}
+
+// IsPremium reports whether the user has the default (premium) entitlement.
+func (s *EntitlementService) IsPremium(userID uuid.UUID) (bool, error) {
+	return s.Has(userID, s.defaultEntitlement)
+}
+
+// Invalidate drops the cached entitlements of the given users.
+func (s *EntitlementService) Invalidate(userIDs ...uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range userIDs {
+		delete(s.cache, id)
+	}
+}
+
+// InvalidateAppUsers drops the cached entitlements of every user linked to the given
+// RevenueCat app user IDs, whether directly (app_user_id is our user ID), through an alias or
+// through a subscription.
+func (s *EntitlementService) InvalidateAppUsers(appUserIDs ...string) {
+	var userIDs []uuid.UUID
+	for _, id := range appUserIDs {
+		if userID, err := uuid.Parse(id); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	var aliased []uuid.UUID
+	if err := s.db.Model(&models.RevenueCatAlias{}).
+		Where("app_user_id IN ?", appUserIDs).
+		Distinct().Pluck("user_id", &aliased).Error; err == nil {
+		userIDs = append(userIDs, aliased...)
+	}
+	var linked []uuid.UUID
+	if err := s.db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ? AND user_id IS NOT NULL", appUserIDs).
+		Distinct().Pluck("user_id", &linked).Error; err == nil {
+		userIDs = append(userIDs, linked...)
+	}
+	s.Invalidate(userIDs...)
+}
+
+// Status describes the user's subscription for the client.
+func (s *EntitlementService) Status(userID uuid.UUID) (*dto.SubscriptionStatusResponse, error) {
+	entitlements, err := s.Entitlements(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &dto.SubscriptionStatusResponse{
+		Entitlements: make([]dto.EntitlementResponse, 0, len(entitlements)),
+	}
+	for id, until := range entitlements {
+		status.Entitlements = append(status.Entitlements, dto.EntitlementResponse{ID: id, ExpiresAt: until})
+		if id == s.defaultEntitlement {
+			status.IsPremium = true
+		}
+	}
+
+	var sub models.Subscription
+	err = s.db.Where("user_id = ?", userID).Order("current_period_end DESC NULLS FIRST").Limit(1).Find(&sub).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subscription: %w", err)
+	}
+	if sub.ID != uuid.Nil {
+		status.Subscription = &dto.SubscriptionResponse{
+			ProductID:            sub.ProductID,
+			PendingProductID:     sub.PendingProductID,
+			Status:               sub.Status,
+			WillRenew:            sub.CurrentPeriodEnd != nil && (sub.Status == models.SubscriptionActive || sub.Status == models.SubscriptionGracePeriod),
+			CurrentPeriodEnd:     sub.CurrentPeriodEnd,
+			GracePeriodExpiresAt: sub.GracePeriodExpiresAt,
+			AutoResumeAt:         sub.AutoResumeAt,
+			Entitlements:         sub.Entitlements(),
+		}
+		if status.Subscription.Entitlements == nil {
+			status.Subscription.Entitlements = []string{s.defaultEntitlement}
+		}
+	}
+	return status, nil
+}
+
+// joinEntitlements stores entitlement IDs as a comma-separated column.
+func joinEntitlements(ids []string) string {
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+)
+
+func TestEntitledUntil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past, future, later := now.Add(-time.Hour), now.Add(time.Hour), now.Add(48*time.Hour)
+
+	tests := []struct {
+		name         string
+		sub          models.Subscription
+		wantEntitled bool
+		wantUntil    *time.Time // nil: never ends
+	}{
+		{"active", models.Subscription{Status: models.SubscriptionActive, CurrentPeriodEnd: &future}, true, &future},
+		{"lifetime", models.Subscription{Status: models.SubscriptionActive}, true, nil},
+		{"cancelled lifetime", models.Subscription{Status: models.SubscriptionCancelled}, true, nil},
+		{"period over", models.Subscription{Status: models.SubscriptionActive, CurrentPeriodEnd: &past}, false, &past},
+		{"grace period", models.Subscription{Status: models.SubscriptionGracePeriod, CurrentPeriodEnd: &past, GracePeriodExpiresAt: &later}, true, &later},
+		{"expired", models.Subscription{Status: models.SubscriptionExpired, CurrentPeriodEnd: &future}, false, nil},
+		{"paused", models.Subscription{Status: models.SubscriptionPaused}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, entitled := entitledUntil(&tt.sub, now)
+			if entitled != tt.wantEntitled {
+				t.Errorf("entitled = %v, want %v", entitled, tt.wantEntitled)
+			}
+			if formatTime(until) != formatTime(tt.wantUntil) {
+				t.Errorf("until = %q, want %q", formatTime(until), formatTime(tt.wantUntil))
+			}
+		})
+	}
+}
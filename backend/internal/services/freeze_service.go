@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FreezeMilestoneDays is the streak length at which (and at every multiple of which) a freeze is earned.
+// How many freezes a user can hold and claim comes from their plan (models.PlanLimits).
+const FreezeMilestoneDays = 30
+
+var (
+	ErrFreezeCapReached   = errors.New("maximum freezes reached")
+	ErrPremiumRequired    = errors.New("an active premium subscription is required")
+	ErrMonthlyFreezeLimit = errors.New("monthly premium freeze allowance already claimed")
+)
+
+// FreezeService manages streak freeze grants and the freeze ledger.
+type FreezeService struct {
+	db       *gorm.DB
+	products map[string]int // RevenueCat product ID -> freezes granted per purchase
+	plans    *PlanService
+}
+
+// NewFreezeService takes the freeze product list as "product_id[:quantity],..." (quantity defaults to 1).
+func NewFreezeService(db *gorm.DB, freezeProducts string, plans *PlanService) *FreezeService {
+	products := make(map[string]int)
+	for _, entry := range strings.Split(freezeProducts, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, qty := entry, 1
+		if i := strings.LastIndex(entry, ":"); i > 0 {
+			if n, err := strconv.Atoi(entry[i+1:]); err == nil && n > 0 {
+				id, qty = entry[:i], n
+			}
+		}
+		products[id] = qty
+	}
+	return &FreezeService{db: db, products: products, plans: plans}
+}
+
+// FreezesForProduct returns how many freezes a purchase of productID grants, or 0 if it isn't a freeze product.
+func (s *FreezeService) FreezesForProduct(productID string) int {
+	return s.products[productID]
+}
+
+// CreditPurchase grants the freezes bought in a RevenueCat transaction. Redelivered events
+// with the same transaction ID are ignored. It reports whether anything was credited.
+func (s *FreezeService) CreditPurchase(userID uuid.UUID, productID, transactionID string) (bool, error) {
+	return s.creditPurchase(s.db, userID, productID, transactionID)
+}
+
+// creditPurchase is CreditPurchase within the caller's transaction.
+func (s *FreezeService) creditPurchase(db *gorm.DB, userID uuid.UUID, productID, transactionID string) (bool, error) {
+	count := s.FreezesForProduct(productID)
+	if count == 0 {
+		return false, nil
+	}
+
+	credited := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		streak, err := lockStreak(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		ref := "purchase:" + transactionID
+		granted, err := grantFreezes(tx, streak, count, models.FreezeSourcePurchase, &ref, 0)
+		if err != nil || granted == 0 {
+			return err
+		}
+		credited = true
+		return tx.Save(streak).Error
+	})
+	return credited, err
+}
+
+// ClaimPremiumFreeze lets a subscriber add a freeze, up to their plan's monthly allowance and freeze cap.
+func (s *FreezeService) ClaimPremiumFreeze(userID uuid.UUID) (*models.SnapStreak, error) {
+	limits, err := s.plans.Limits(userID)
+	if err != nil {
+		return nil, err
+	}
+	if limits.MonthlyFreezeClaims == 0 {
+		return nil, ErrPremiumRequired
+	}
+
+	var streak *models.SnapStreak
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var lockErr error
+		streak, lockErr = lockStreak(tx, userID)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		now := time.Now()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		var claimed int64
+		if err := tx.Model(&models.FreezeTransaction{}).
+			Where("user_id = ? AND source = ? AND created_at >= ?", userID, models.FreezeSourcePremium, monthStart).
+			Count(&claimed).Error; err != nil {
+			return err
+		}
+		if claimed >= int64(limits.MonthlyFreezeClaims) {
+			return fmt.Errorf("%w (%d)", ErrMonthlyFreezeLimit, limits.MonthlyFreezeClaims)
+		}
+
+		granted, err := grantFreezes(tx, streak, 1, models.FreezeSourcePremium, nil, limits.MaxFreezes)
+		if err != nil {
+			return err
+		}
+		if granted == 0 {
+			return fmt.Errorf("%w (%d)", ErrFreezeCapReached, limits.MaxFreezes)
+		}
+		return tx.Save(streak).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return streak, nil
+}
+
+// History returns the user's most recent freeze ledger entries.
+func (s *FreezeService) History(userID uuid.UUID, limit int) ([]models.FreezeTransaction, error) {
+	var entries []models.FreezeTransaction
+	err := s.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// lockStreak loads the user's streak row FOR UPDATE inside tx, creating an empty one if needed.
+func lockStreak(tx *gorm.DB, userID uuid.UUID) (*models.SnapStreak, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.SnapStreak{ID: uuid.New(), UserID: userID}).Error; err != nil {
+		return nil, fmt.Errorf("failed to ensure streak: %w", err)
+	}
+
+	var streak models.SnapStreak
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).First(&streak).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock streak: %w", err)
+	}
+	return &streak, nil
+}
+
+// grantFreezes adds up to count freezes to streak and records the grant in the ledger.
+// With a maxFreezes cap (0 = uncapped) the balance never exceeds it. A reference already in
+// the ledger grants nothing. The caller persists streak. Returns the number actually granted.
+func grantFreezes(tx *gorm.DB, streak *models.SnapStreak, count int, source string, reference *string, maxFreezes int) (int, error) {
+	if maxFreezes > 0 && streak.FreezesAvailable+count > maxFreezes {
+		count = maxFreezes - streak.FreezesAvailable
+	}
+	if count <= 0 {
+		return 0, nil
+	}
+
+	entry := models.FreezeTransaction{
+		ID:        uuid.New(),
+		UserID:    streak.UserID,
+		Delta:     count,
+		Source:    source,
+		Reference: reference,
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to record freeze grant: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, nil
+	}
+
+	streak.FreezesAvailable += count
+	return count, nil
+}
+
+// useFreezes spends one freeze per date in dates, recording each frozen day and its ledger
+// entry. The caller persists streak.
+func useFreezes(tx *gorm.DB, streak *models.SnapStreak, dates []string) error {
+	for _, date := range dates {
+		ref := "frozen:" + date
+		entry := models.FreezeTransaction{
+			ID:        uuid.New(),
+			UserID:    streak.UserID,
+			Delta:     -1,
+			Source:    models.FreezeSourceUsed,
+			Reference: &ref,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to record freeze use: %w", err)
+		}
+
+		day := models.FrozenDay{ID: uuid.New(), UserID: streak.UserID, Date: date}
+		if err := tx.Create(&day).Error; err != nil {
+			return fmt.Errorf("failed to record frozen day: %w", err)
+		}
+	}
+
+	streak.FreezesAvailable -= len(dates)
+	streak.FreezesUsed += len(dates)
+	streak.LastFreezeDate = time.Now()
+	return nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// FitImageToPlan downsizes a saved upload in place unless the user's plan keeps original
+// resolution. Formats the standard library can't decode (HEIC) are kept as uploaded.
+func (s *SnapService) FitImageToPlan(userID uuid.UUID, path string) error {
+	limits, err := s.plans.Limits(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get plan: %w", err)
+	}
+	if limits.OriginalResolution || limits.MaxImageDimension < 1 {
+		return nil
+	}
+	return downscaleImage(path, limits.MaxImageDimension)
+}
+
+// downscaleImage rewrites the JPEG or PNG at path so its longest side is at most maxDim,
+// averaging the source pixels behind each output pixel. Re-encoding drops metadata (EXIF).
+func downscaleImage(path string, maxDim int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	src, format, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return nil // not a format we can resize; store as uploaded
+	}
+
+	b := src.Bounds()
+	longest := max(b.Dx(), b.Dy())
+	if longest <= maxDim {
+		return nil
+	}
+	w := max(1, b.Dx()*maxDim/longest)
+	h := max(1, b.Dy()*maxDim/longest)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+(y+1)*b.Dy()/h
+		for x := 0; x < w; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+(x+1)*b.Dx()/w
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".resize-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if format == "png" {
+		err = png.Encode(tmp, dst)
+	} else {
+		err = jpeg.Encode(tmp, dst, &jpeg.Options{Quality: 90})
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode resized image: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // register decoders for uploaded snaps
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Brand colors used by server-rendered images.
+var (
+	brandOrange = color.RGBA{R: 0xFF, G: 0x6B, B: 0x35, A: 0xFF}
+	brandPink   = color.RGBA{R: 0xE9, G: 0x1E, B: 0x63, A: 0xFF}
+	tileColor   = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x33}
+)
+
+// loadSnapImage decodes a stored snap image. Only local uploads ("/uploads/...") in a format
+// the standard library can decode (JPEG, PNG) are supported.
+func loadSnapImage(imageURL string) (image.Image, error) {
+	if !strings.HasPrefix(imageURL, "/uploads/") {
+		return nil, os.ErrNotExist
+	}
+	f, err := os.Open(filepath.Join(".", filepath.FromSlash(imageURL)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// drawThumbnail draws src center-cropped to a square and scaled into rect (nearest neighbour).
+// A nil src draws a translucent placeholder tile instead.
+func drawThumbnail(dst draw.Image, rect image.Rectangle, src image.Image) {
+	if src == nil {
+		draw.Draw(dst, rect, &image.Uniform{C: tileColor}, image.Point{}, draw.Over)
+		return
+	}
+
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+
+	w, h := rect.Dx(), rect.Dy()
+	for y := 0; y < h; y++ {
+		sy := crop.Min.Y + y*side/h
+		for x := 0; x < w; x++ {
+			sx := crop.Min.X + x*side/w
+			dst.Set(rect.Min.X+x, rect.Min.Y+y, src.At(sx, sy))
+		}
+	}
+}
+
+// fillGradient fills dst with a vertical gradient from top to bottom.
+func fillGradient(dst *image.RGBA, top, bottom color.RGBA) {
+	b := dst.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		t := float64(y-b.Min.Y) / float64(max(b.Dy()-1, 1))
+		c := color.RGBA{
+			R: uint8(float64(top.R) + t*(float64(bottom.R)-float64(top.R))),
+			G: uint8(float64(top.G) + t*(float64(bottom.G)-float64(top.G))),
+			B: uint8(float64(top.B) + t*(float64(bottom.B)-float64(top.B))),
+			A: 0xFF,
+		}
+		draw.Draw(dst, image.Rect(b.Min.X, y, b.Max.X, y+1), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+}
+
+// glyphs is a 5x7 bitmap font covering the characters used on rendered cards.
+var glyphs = map[rune][7]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C': {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D': {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E': {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F': {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I': {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J': {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M': {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T': {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U': {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X': {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y': {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z': {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'!': {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+	'#': {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'/': {"....#", "....#", "...#.", "..#..", ".#...", "#....", "#...."},
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+}
+
+// textWidth returns the rendered width of s at the given pixel scale.
+func textWidth(s string, scale int) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return (n*6 - 1) * scale
+}
+
+// drawText draws s (upper-cased) with the bitmap font; each font pixel is scale×scale.
+// Characters without a glyph are skipped, leaving a blank cell.
+func drawText(dst draw.Image, s string, x, y, scale int, c color.Color) {
+	src := &image.Uniform{C: c}
+	for i, r := range []rune(strings.ToUpper(s)) {
+		glyph, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		ox := x + i*6*scale
+		for row, line := range glyph {
+			for col, px := range line {
+				if px != '#' {
+					continue
+				}
+				cell := image.Rect(ox+col*scale, y+row*scale, ox+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(dst, cell, src, image.Point{}, draw.Over)
+			}
+		}
+	}
+}
+
+// drawCenteredText draws s horizontally centered in dst.
+func drawCenteredText(dst draw.Image, s string, y, scale int, c color.Color) {
+	x := (dst.Bounds().Dx() - textWidth(s, scale)) / 2
+	drawText(dst, s, x, y, scale, c)
+}
@@ -10,12 +10,15 @@ import (
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
 	ErrReportNotFound = errors.New("report not found")
 	ErrAlreadyBlocked = errors.New("user already blocked")
 	ErrSelfBlock      = errors.New("cannot block yourself")
+
+	ErrInvalidReportStatus = errors.New("invalid status: must be reviewed, actioned, or dismissed")
 )
 
 // ProfanityPatterns is a basic regex-based content filter (Apple Guideline 1.2).
@@ -100,24 +103,44 @@ func (s *ModerationService) ListReports(status string, limit, offset int) ([]mod
 	return reports, total, nil
 }
 
-func (s *ModerationService) ActionReport(reportID uuid.UUID, req *dto.ActionReportRequest) error {
+// ActionReport sets a report's review status and note, auditing the change with audit.
+func (s *ModerationService) ActionReport(reportID uuid.UUID, req *dto.ActionReportRequest, audit *AuditEntry) (before, after *models.Report, err error) {
 	validStatuses := map[string]bool{"reviewed": true, "actioned": true, "dismissed": true}
 	if !validStatuses[req.Status] {
-		return errors.New("invalid status: must be reviewed, actioned, or dismissed")
+		return nil, nil, ErrInvalidReportStatus
 	}
 
-	result := s.db.Model(&models.Report{}).
-		Where("id = ?", reportID).
-		Updates(map[string]interface{}{
+	// Lock the report so the returned before/after values describe exactly this change
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var report models.Report
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&report, "id = ?", reportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			return err
+		}
+		previous := report
+		before = &previous
+
+		report.Status = req.Status
+		report.AdminNote = req.AdminNote
+		if err := tx.Model(&report).Updates(map[string]interface{}{
 			"status":     req.Status,
 			"admin_note": req.AdminNote,
-		})
-
-	if result.RowsAffected == 0 {
-		return ErrReportNotFound
+		}).Error; err != nil {
+			return err
+		}
+		after = &report
+		return audit.record(tx, reportAuditFields(before), reportAuditFields(after))
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	return before, after, nil
+}
 
-	return result.Error
+func reportAuditFields(r *models.Report) map[string]interface{} {
+	return map[string]interface{}{"status": r.Status, "admin_note": r.AdminNote}
 }
 
 // --- Blocking ---
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Notifier delivers a stored notification outside the app (push, email, ...).
+type Notifier interface {
+	Send(user *models.User, n *models.Notification) error
+}
+
+// LogNotifier writes notifications to the server log. Used when no real channel is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Send(user *models.User, n *models.Notification) error {
+	log.Printf("notification [%s] to %s: %s — %s", n.Type, user.ID, n.Title, n.Body)
+	return nil
+}
+
+// ExpoPushNotifier sends push notifications through the Expo push service.
+type ExpoPushNotifier struct {
+	client *http.Client
+}
+
+func NewExpoPushNotifier() *ExpoPushNotifier {
+	return &ExpoPushNotifier{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *ExpoPushNotifier) Send(user *models.User, n *models.Notification) error {
+	if user.PushToken == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"to":    user.PushToken,
+		"title": n.Title,
+		"body":  n.Body,
+		"sound": "default",
+		"data":  map[string]string{"type": n.Type, "notification_id": n.ID.String()},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Post("https://exp.host/--/api/v2/push/send", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("expo push request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("expo push returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier sends notifications as plain-text email over SMTP.
+type EmailNotifier struct {
+	cfg *config.Config
+}
+
+func NewEmailNotifier(cfg *config.Config) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg}
+}
+
+func (e *EmailNotifier) Send(user *models.User, n *models.Notification) error {
+	// Apple Sign-In users without a shared email have nothing we can deliver to
+	if user.Email == "" {
+		return nil
+	}
+
+	msg := "From: " + e.cfg.SMTPFrom + "\r\n" +
+		"To: " + user.Email + "\r\n" +
+		"Subject: " + n.Title + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" +
+		n.Body + "\r\n"
+
+	var auth smtp.Auth
+	if e.cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", e.cfg.SMTPUser, e.cfg.SMTPPassword, e.cfg.SMTPHost)
+	}
+
+	return smtp.SendMail(e.cfg.SMTPHost+":"+e.cfg.SMTPPort, auth, e.cfg.SMTPFrom, []string{user.Email}, []byte(msg))
+}
+
+type NotificationService struct {
+	db        *gorm.DB
+	notifiers []Notifier
+}
+
+func NewNotificationService(db *gorm.DB, notifiers ...Notifier) *NotificationService {
+	return &NotificationService{db: db, notifiers: notifiers}
+}
+
+// Notify stores an in-app notification and fans it out to every configured channel.
+// Delivery failures are logged; only failing to store the notification is an error.
+func (s *NotificationService) Notify(userID uuid.UUID, notificationType, title, body string) (*models.Notification, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	n := models.Notification{
+		ID:     uuid.New(),
+		UserID: userID,
+		Type:   notificationType,
+		Title:  title,
+		Body:   body,
+	}
+	if err := s.db.Create(&n).Error; err != nil {
+		return nil, fmt.Errorf("failed to store notification: %w", err)
+	}
+
+	for _, notifier := range s.notifiers {
+		if err := notifier.Send(&user, &n); err != nil {
+			log.Printf("warning: failed to deliver notification %s to user %s: %v", n.ID, userID, err)
+		}
+	}
+
+	return &n, nil
+}
+
+// NotifyOnce sends a notification at most once per user, type and local day (YYYY-MM-DD).
+// It reports whether this call delivered it; a receipt row guards against duplicate sends
+// from concurrent jobs or replicas, and is released again if storing the notification fails.
+func (s *NotificationService) NotifyOnce(userID uuid.UUID, notificationType, localDate, title, body string) (bool, error) {
+	receipt := models.NotificationReceipt{UserID: userID, Type: notificationType, LocalDate: localDate}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&receipt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim notification receipt: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if _, err := s.Notify(userID, notificationType, title, body); err != nil {
+		s.db.Delete(&receipt)
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns the user's most recent notifications and their unread count.
+func (s *NotificationService) List(userID uuid.UUID, limit int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var unread int64
+
+	if err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&unread).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := s.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&notifications).Error
+
+	return notifications, unread, err
+}
+
+// MarkAllRead marks every unread notification of the user as read.
+func (s *NotificationService) MarkAllRead(userID uuid.UUID) error {
+	return s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
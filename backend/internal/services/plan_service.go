@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlanService resolves a user's plan limits from their entitlements.
+type PlanService struct {
+	entitlements *EntitlementService
+	plans        []models.PlanLimits
+}
+
+// NewPlanService loads the plan-limits table from plansFile (a JSON array) when set, falling back
+// to models.DefaultPlans with the entitlement service's premium entitlement.
+func NewPlanService(entitlements *EntitlementService, plansFile string) *PlanService {
+	plans := models.DefaultPlans(entitlements.DefaultEntitlement())
+	if plansFile != "" {
+		loaded, err := loadPlans(plansFile)
+		if err != nil {
+			log.Printf("Warning: Could not load plans from %s, using defaults: %v", plansFile, err)
+		} else {
+			plans = loaded
+		}
+	}
+	return &PlanService{entitlements: entitlements, plans: plans}
+}
+
+func loadPlans(path string) ([]models.PlanLimits, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plans []models.PlanLimits
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 || plans[0].Entitlement != "" {
+		return nil, fmt.Errorf("the first plan must have no entitlement")
+	}
+	for i, p := range plans {
+		if i > 0 && p.Entitlement == "" {
+			return nil, fmt.Errorf("plan %q needs an entitlement", p.Plan)
+		}
+		if p.Plan == "" || p.MaxFreezes < 0 || p.MonthlyFreezeClaims < 0 || p.CalendarHistoryDays < 0 {
+			return nil, fmt.Errorf("invalid plan %q", p.Plan)
+		}
+		if !p.OriginalResolution && p.MaxImageDimension < 1 {
+			return nil, fmt.Errorf("plan %q needs max_image_dimension without original_resolution", p.Plan)
+		}
+	}
+	return plans, nil
+}
+
+// Limits returns the limits of the user's plan. A nil service (e.g. in the admin CLI) applies
+// the free plan to everyone.
+func (s *PlanService) Limits(userID uuid.UUID) (*models.PlanLimits, error) {
+	if s == nil {
+		plan := models.FreePlan
+		return &plan, nil
+	}
+
+	entitlements, err := s.entitlements.Entitlements(userID)
+	if err != nil {
+		return nil, err
+	}
+	plan := s.plans[0]
+	for _, p := range s.plans[1:] {
+		if _, ok := entitlements[p.Entitlement]; ok {
+			plan = p
+		}
+	}
+	return &plan, nil
+}
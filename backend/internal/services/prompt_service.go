@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultPromptLocale = "en"
+
+var (
+	ErrPromptNotFound  = errors.New("prompt not found")
+	ErrNoPrompts       = errors.New("no prompts available")
+	ErrPromptNotToday  = errors.New("prompt is not today's prompt")
+	ErrPromptScheduled = errors.New("a prompt is already scheduled for that date and locale")
+)
+
+type PromptService struct {
+	db *gorm.DB
+}
+
+func NewPromptService(db *gorm.DB) *PromptService {
+	return &PromptService{db: db}
+}
+
+// SeedDefaults fills the rotation with models.DefaultPrompts when no prompts exist yet.
+func (s *PromptService) SeedDefaults() error {
+	var count int64
+	if err := s.db.Model(&models.Prompt{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	prompts := make([]models.Prompt, len(models.DefaultPrompts))
+	for i, p := range models.DefaultPrompts {
+		p.ID = uuid.New()
+		p.Locale = defaultPromptLocale
+		p.InRotation = true
+		prompts[i] = p
+	}
+	return s.db.Create(&prompts).Error
+}
+
+// TodayPrompt returns the prompt for the user's local day. A prompt scheduled for that date
+// wins; otherwise one is picked from the locale's rotation, deterministically by date so all
+// users see the same one. Locales without prompts fall back to English.
+func (s *PromptService) TodayPrompt(userID uuid.UUID, locale string, now time.Time) (*models.Prompt, string, error) {
+	var user models.User
+	if err := s.db.Select("id", "timezone").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, "", ErrUserNotFound
+	}
+	day := startOfDay(now, user.Location())
+	date := day.Format("2006-01-02")
+
+	locales := []string{defaultPromptLocale}
+	if locale = normalizeLocale(locale); locale != defaultPromptLocale {
+		locales = []string{locale, defaultPromptLocale}
+	}
+
+	for _, loc := range locales {
+		prompt, err := s.promptFor(loc, date, day)
+		if errors.Is(err, ErrNoPrompts) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return prompt, date, nil
+	}
+	return nil, "", ErrNoPrompts
+}
+
+func (s *PromptService) promptFor(locale, date string, day time.Time) (*models.Prompt, error) {
+	var prompt models.Prompt
+	err := s.db.Where("locale = ? AND scheduled_date = ?", locale, date).First(&prompt).Error
+	if err == nil {
+		return &prompt, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load scheduled prompt: %w", err)
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Prompt{}).Where("locale = ? AND in_rotation = ?", locale, true).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count prompts: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrNoPrompts
+	}
+
+	// Days since the Unix epoch, independent of the user's UTC offset
+	dayNumber := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+	err = s.db.Where("locale = ? AND in_rotation = ?", locale, true).
+		Order("created_at ASC, id ASC").
+		Offset(int(dayNumber % count)).
+		First(&prompt).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick rotation prompt: %w", err)
+	}
+	return &prompt, nil
+}
+
+// CheckTodayPrompt verifies that promptID is the prompt its locale shows on the given local day,
+// so a snap can't answer a past or future prompt.
+func (s *PromptService) CheckTodayPrompt(promptID uuid.UUID, day time.Time) error {
+	var prompt models.Prompt
+	if err := s.db.Select("id", "locale").First(&prompt, "id = ?", promptID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPromptNotFound
+		}
+		return fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	today, err := s.promptFor(prompt.Locale, day.Format("2006-01-02"), day)
+	if errors.Is(err, ErrNoPrompts) {
+		return ErrPromptNotToday
+	}
+	if err != nil {
+		return err
+	}
+	if today.ID != prompt.ID {
+		return ErrPromptNotToday
+	}
+	return nil
+}
+
+// GetPrompt returns a prompt by ID.
+func (s *PromptService) GetPrompt(promptID uuid.UUID) (*models.Prompt, error) {
+	var prompt models.Prompt
+	if err := s.db.First(&prompt, "id = ?", promptID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// GetPromptSnaps returns snaps answering a prompt, newest first, hiding users the viewer blocked.
+func (s *PromptService) GetPromptSnaps(promptID, viewerID uuid.UUID, limit, offset int) ([]models.Snap, int64, error) {
+	var snaps []models.Snap
+	var total int64
+
+	query := s.db.Model(&models.Snap{}).
+		Where("prompt_id = ?", promptID).
+		Where("user_id NOT IN (?)", s.db.Model(&models.Block{}).Select("blocked_id").Where("blocker_id = ?", viewerID))
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("snap_date DESC").Limit(limit).Offset(offset).Find(&snaps).Error
+	return snaps, total, err
+}
+
+// --- Admin ---
+
+// ListPrompts returns prompts for a locale (all locales when empty), scheduled ones first by date.
+func (s *PromptService) ListPrompts(locale string, limit, offset int) ([]models.Prompt, int64, error) {
+	var prompts []models.Prompt
+	var total int64
+
+	query := s.db.Model(&models.Prompt{})
+	if locale != "" {
+		query = query.Where("locale = ?", normalizeLocale(locale))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("scheduled_date DESC NULLS LAST, created_at ASC").
+		Limit(limit).Offset(offset).Find(&prompts).Error
+	return prompts, total, err
+}
+
+// CreatePrompt adds a prompt to the rotation, auditing it with audit.
+func (s *PromptService) CreatePrompt(req *dto.PromptRequest, audit *AuditEntry) (*models.Prompt, error) {
+	prompt := models.Prompt{ID: uuid.New(), InRotation: true}
+	if err := applyPromptRequest(&prompt, req); err != nil {
+		return nil, err
+	}
+	if audit != nil {
+		audit.TargetID = prompt.ID.String()
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&prompt).Error; err != nil {
+			return err
+		}
+		return audit.record(tx, nil, &prompt)
+	})
+	if err != nil {
+		if isUniqueViolation(err, "idx_prompt_locale_date") {
+			return nil, ErrPromptScheduled
+		}
+		return nil, fmt.Errorf("failed to create prompt: %w", err)
+	}
+	return &prompt, nil
+}
+
+// UpdatePrompt edits a prompt and returns it as it was before and after the change, auditing
+// the change with audit.
+func (s *PromptService) UpdatePrompt(promptID uuid.UUID, req *dto.PromptRequest, audit *AuditEntry) (before, after *models.Prompt, err error) {
+	prompt, err := s.GetPrompt(promptID)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous := *prompt
+	if err := applyPromptRequest(prompt, req); err != nil {
+		return nil, nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(prompt).Error; err != nil {
+			return err
+		}
+		return audit.record(tx, &previous, prompt)
+	})
+	if err != nil {
+		if isUniqueViolation(err, "idx_prompt_locale_date") {
+			return nil, nil, ErrPromptScheduled
+		}
+		return nil, nil, fmt.Errorf("failed to update prompt: %w", err)
+	}
+	return &previous, prompt, nil
+}
+
+// DeletePrompt removes a prompt and returns what was deleted, auditing the deletion with audit.
+// Snaps keep their prompt_id so history is not rewritten.
+func (s *PromptService) DeletePrompt(promptID uuid.UUID, audit *AuditEntry) (*models.Prompt, error) {
+	var prompt models.Prompt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Returning{}).Delete(&prompt, "id = ?", promptID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete prompt: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPromptNotFound
+		}
+		return audit.record(tx, &prompt, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+func applyPromptRequest(prompt *models.Prompt, req *dto.PromptRequest) error {
+	if req.Text != nil {
+		text := strings.TrimSpace(*req.Text)
+		if text == "" || len(text) > 100 {
+			return errors.New("text is required and must be at most 100 characters")
+		}
+		prompt.Text = text
+	}
+	if prompt.Text == "" {
+		return errors.New("text is required and must be at most 100 characters")
+	}
+	if req.Emoji != nil {
+		prompt.Emoji = *req.Emoji
+	}
+	if req.Category != nil {
+		prompt.Category = *req.Category
+	}
+	if req.Locale != nil {
+		prompt.Locale = normalizeLocale(*req.Locale)
+	}
+	if prompt.Locale == "" {
+		prompt.Locale = defaultPromptLocale
+	}
+	if req.ScheduledDate != nil {
+		if *req.ScheduledDate == "" {
+			prompt.ScheduledDate = nil
+		} else {
+			if _, err := time.Parse("2006-01-02", *req.ScheduledDate); err != nil {
+				return errors.New("scheduled_date must be YYYY-MM-DD")
+			}
+			date := *req.ScheduledDate
+			prompt.ScheduledDate = &date
+		}
+	}
+	if req.InRotation != nil {
+		prompt.InRotation = *req.InRotation
+	}
+	return nil
+}
+
+// normalizeLocale reduces "tr-TR" or "TR" to the primary language tag "tr".
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locale = locale[:i]
+	}
+	if locale == "" {
+		return defaultPromptLocale
+	}
+	return locale
+}
+
+// isUniqueViolation reports whether err is a Postgres unique violation on the named index.
+func isUniqueViolation(err error, index string) bool {
+	return err != nil && strings.Contains(err.Error(), "duplicate key") && strings.Contains(err.Error(), index)
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recap collage layout (portrait feed post).
+const (
+	recapWidth     = 1080
+	recapHeight    = 1350
+	recapTopSnaps  = 9
+	recapThumbSize = 320
+	recapThumbGap  = 20
+)
+
+var ErrRecapNotFound = errors.New("recap not found")
+
+// RecapService generates monthly and yearly recaps once a period has ended in the user's time zone.
+type RecapService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+	dir           string
+}
+
+// NewRecapService stores collage images in dir, which must not be publicly served.
+func NewRecapService(db *gorm.DB, notifications *NotificationService, dir string) *RecapService {
+	return &RecapService{db: db, notifications: notifications, dir: dir}
+}
+
+// recapPeriod is a local [Start, End) range.
+type recapPeriod struct {
+	Kind  string
+	Key   string
+	Start time.Time
+	End   time.Time
+}
+
+// Title returns a display title such as "September 2026" or "2026".
+func (p recapPeriod) Title() string {
+	if p.Kind == models.RecapYear {
+		return p.Key
+	}
+	return p.Start.Format("January 2006")
+}
+
+// endedPeriods returns the month that ended most recently in loc, plus its year when that month was December.
+func endedPeriods(now time.Time, loc *time.Location) []recapPeriod {
+	local := now.In(loc)
+	thisMonth := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+
+	periods := []recapPeriod{{Kind: models.RecapMonth, Key: lastMonth.Format("2006-01"), Start: lastMonth, End: thisMonth}}
+	if lastMonth.Month() == time.December {
+		year := time.Date(lastMonth.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		periods = append(periods, recapPeriod{Kind: models.RecapYear, Key: year.Format("2006"), Start: year, End: thisMonth})
+	}
+	return periods
+}
+
+// GenerateRecaps creates the recaps for every user who snapped during a period that has just
+// ended in their time zone. Existing recaps are skipped, so the job can run as often as needed.
+func (s *RecapService) GenerateRecaps(ctx context.Context, now time.Time) error {
+	var candidates []struct {
+		UserID   uuid.UUID
+		Timezone string
+	}
+	// Anyone who snapped in the last ~13 months may be owed a month or year recap
+	err := s.db.WithContext(ctx).
+		Table("snaps").
+		Distinct("snaps.user_id, users.timezone").
+		Joins("JOIN users ON users.id = snaps.user_id AND users.deleted_at IS NULL").
+		Where("snaps.deleted_at IS NULL AND snaps.snap_date >= ?", now.AddDate(-1, -1, -1)).
+		Scan(&candidates).Error
+	if err != nil {
+		return fmt.Errorf("failed to load recap candidates: %w", err)
+	}
+
+	created := 0
+	for _, c := range candidates {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		loc := (&models.User{Timezone: c.Timezone}).Location()
+		for _, period := range endedPeriods(now, loc) {
+			recap, err := s.generate(c.UserID, period)
+			if err != nil {
+				log.Printf("warning: failed to generate %s recap %s for user %s: %v", period.Kind, period.Key, c.UserID, err)
+				continue
+			}
+			if recap == nil {
+				continue
+			}
+			created++
+			s.notifyRecap(recap, period)
+		}
+	}
+
+	if created > 0 {
+		log.Printf("Generated %d recaps", created)
+	}
+	return nil
+}
+
+// generate builds and stores one recap. Returns nil if it already exists or the user didn't snap in the period.
+func (s *RecapService) generate(userID uuid.UUID, period recapPeriod) (*models.Recap, error) {
+	var exists int64
+	if err := s.db.Model(&models.Recap{}).
+		Where("user_id = ? AND period_key = ?", userID, period.Key).
+		Count(&exists).Error; err != nil {
+		return nil, err
+	}
+	if exists > 0 {
+		return nil, nil
+	}
+
+	summary, err := s.summarize(userID, period)
+	if err != nil || summary.SnapCount == 0 {
+		return nil, err
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	recap := models.Recap{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Period:    period.Kind,
+		PeriodKey: period.Key,
+		Summary:   string(data),
+	}
+	recap.ImagePath = filepath.Join(s.dir, recap.ID.String()+".png")
+	if err := s.renderCollage(recap.ImagePath, period, summary); err != nil {
+		// The recap is still useful without its image
+		log.Printf("warning: failed to render recap collage for user %s: %v", userID, err)
+		recap.ImagePath = ""
+	}
+
+	if err := s.db.Create(&recap).Error; err != nil {
+		if recap.ImagePath != "" {
+			os.Remove(recap.ImagePath)
+		}
+		if isUniqueViolation(err, "idx_recap_user_period") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &recap, nil
+}
+
+// summarize aggregates the user's snaps, likes, filters and streak activity within the period.
+func (s *RecapService) summarize(userID uuid.UUID, period recapPeriod) (*dto.RecapSummary, error) {
+	tz := period.Start.Location().String()
+	summary := &dto.RecapSummary{
+		Days:          daysBetween(period.Start, period.End),
+		FilterCounts:  map[string]int{},
+		TopLikedSnaps: []dto.RecapSnap{},
+	}
+
+	inPeriod := s.db.Model(&models.Snap{}).
+		Where("user_id = ? AND snap_date >= ? AND snap_date < ?", userID, period.Start, period.End)
+
+	var totals struct {
+		SnapCount       int
+		TotalLikes      int
+		PromptsAnswered int
+	}
+	if err := inPeriod.Session(&gorm.Session{}).
+		Select("COUNT(*) AS snap_count, COALESCE(SUM(like_count), 0) AS total_likes, COUNT(prompt_id) AS prompts_answered").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate snaps: %w", err)
+	}
+	summary.SnapCount = totals.SnapCount
+	summary.TotalLikes = totals.TotalLikes
+	summary.PromptsAnswered = totals.PromptsAnswered
+	if summary.SnapCount == 0 {
+		return summary, nil
+	}
+
+	var filters []struct {
+		Filter string
+		Count  int
+	}
+	if err := inPeriod.Session(&gorm.Session{}).
+		Select("filter, COUNT(*) AS count").
+		Group("filter").
+		Order("count DESC, filter").
+		Scan(&filters).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate filters: %w", err)
+	}
+	for _, f := range filters {
+		summary.FilterCounts[f.Filter] = f.Count
+		if summary.TopFilter == "" && f.Filter != "none" {
+			summary.TopFilter = f.Filter
+		}
+	}
+
+	var top []models.Snap
+	if err := inPeriod.Session(&gorm.Session{}).
+		Order("like_count DESC, snap_date ASC").
+		Limit(recapTopSnaps).
+		Find(&top).Error; err != nil {
+		return nil, fmt.Errorf("failed to get top snaps: %w", err)
+	}
+	for _, snap := range top {
+		summary.TopLikedSnaps = append(summary.TopLikedSnaps, dto.RecapSnap{
+			ID:        snap.ID.String(),
+			ImageURL:  snap.ImageURL,
+			LikeCount: snap.LikeCount,
+			SnapDate:  snap.SnapDate,
+		})
+	}
+
+	var days []struct{ Day string }
+	if err := inPeriod.Session(&gorm.Session{}).
+		Distinct("to_char(snap_date AT TIME ZONE ?, 'YYYY-MM-DD') AS day", tz).
+		Order("day").
+		Scan(&days).Error; err != nil {
+		return nil, fmt.Errorf("failed to get snapped days: %w", err)
+	}
+	summary.ActiveDays = len(days)
+	run := 0
+	var prev time.Time
+	for _, d := range days {
+		day, err := time.Parse("2006-01-02", d.Day)
+		if err != nil {
+			continue
+		}
+		if !prev.IsZero() && daysBetween(prev, day) == 1 {
+			run++
+		} else {
+			run = 1
+		}
+		summary.LongestStreak = max(summary.LongestStreak, run)
+		prev = day
+	}
+
+	var frozen int64
+	if err := s.db.Model(&models.FrozenDay{}).
+		Where("user_id = ? AND date >= ? AND date < ?", userID, period.Start.Format("2006-01-02"), period.End.Format("2006-01-02")).
+		Count(&frozen).Error; err != nil {
+		return nil, fmt.Errorf("failed to count frozen days: %w", err)
+	}
+	summary.FrozenDays = int(frozen)
+
+	return summary, nil
+}
+
+// renderCollage draws the period title, headline numbers and a grid of the top liked snaps.
+func (s *RecapService) renderCollage(path string, period recapPeriod, summary *dto.RecapSummary) error {
+	img := image.NewRGBA(image.Rect(0, 0, recapWidth, recapHeight))
+	fillGradient(img, brandPink, brandOrange)
+
+	drawCenteredText(img, strings.ToUpper(period.Title()), 70, 10, color.White)
+	drawCenteredText(img, strconv.Itoa(summary.SnapCount)+" SNAPS", 180, 8, color.White)
+	drawCenteredText(img, "BEST STREAK "+strconv.Itoa(summary.LongestStreak), 270, 5, color.White)
+
+	gridWidth := 3*recapThumbSize + 2*recapThumbGap
+	left, top := (recapWidth-gridWidth)/2, 340
+	for i := 0; i < recapTopSnaps; i++ {
+		x := left + (i%3)*(recapThumbSize+recapThumbGap)
+		y := top + (i/3)*(recapThumbSize+recapThumbGap)
+
+		var thumb image.Image
+		if i < len(summary.TopLikedSnaps) {
+			thumb, _ = loadSnapImage(summary.TopLikedSnaps[i].ImageURL)
+		}
+		drawThumbnail(img, image.Rect(x, y, x+recapThumbSize, y+recapThumbSize), thumb)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+func (s *RecapService) notifyRecap(recap *models.Recap, period recapPeriod) {
+	if s.notifications == nil {
+		return
+	}
+
+	title := fmt.Sprintf("✨ Your %s recap is ready", period.Title())
+	body := "See your top snaps, best streak and favorite filters."
+	if _, err := s.notifications.Notify(recap.UserID, models.NotificationRecap, title, body); err != nil {
+		log.Printf("warning: failed to send recap notification to user %s: %v", recap.UserID, err)
+	}
+}
+
+// ListRecaps returns the user's recaps, newest first.
+func (s *RecapService) ListRecaps(userID uuid.UUID) ([]models.Recap, error) {
+	var recaps []models.Recap
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&recaps).Error
+	return recaps, err
+}
+
+// GetRecap returns one of the user's recaps.
+func (s *RecapService) GetRecap(userID, recapID uuid.UUID) (*models.Recap, error) {
+	var recap models.Recap
+	if err := s.db.Where("id = ? AND user_id = ?", recapID, userID).First(&recap).Error; err != nil {
+		return nil, ErrRecapNotFound
+	}
+	return &recap, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReminderService warns users whose streak will break if they don't snap today.
+type ReminderService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+	cutoffMinutes int // minutes after local midnight
+}
+
+func NewReminderService(db *gorm.DB, notifications *NotificationService, cutoff string) *ReminderService {
+	return &ReminderService{
+		db:            db,
+		notifications: notifications,
+		cutoffMinutes: parseClock(cutoff, 20*60),
+	}
+}
+
+type reminderCandidate struct {
+	UserID           uuid.UUID
+	Timezone         string
+	CurrentStreak    int
+	FreezesAvailable int
+	LastSnapDate     time.Time
+}
+
+// SendStreakReminders notifies every user with a live streak who hasn't snapped yet
+// and whose local clock is past the cutoff. Each user gets at most one reminder per
+// local day, enforced across replicas by NotifyOnce.
+func (s *ReminderService) SendStreakReminders(ctx context.Context, now time.Time) error {
+	var candidates []reminderCandidate
+	err := s.db.WithContext(ctx).
+		Table("snap_streaks").
+		Select("snap_streaks.user_id, users.timezone, snap_streaks.current_streak, snap_streaks.freezes_available, snap_streaks.last_snap_date").
+		Joins("JOIN users ON users.id = snap_streaks.user_id AND users.deleted_at IS NULL").
+		Where("snap_streaks.current_streak > 0").
+		Scan(&candidates).Error
+	if err != nil {
+		return fmt.Errorf("failed to load reminder candidates: %w", err)
+	}
+
+	sent := 0
+	for _, c := range candidates {
+		user := models.User{Timezone: c.Timezone}
+		local := now.In(user.Location())
+
+		if local.Hour()*60+local.Minute() < s.cutoffMinutes {
+			continue
+		}
+		if !c.LastSnapDate.Before(startOfDay(now, local.Location())) {
+			continue
+		}
+
+		title, body := streakReminderCopy(c.CurrentStreak, c.FreezesAvailable)
+		delivered, err := s.notifications.NotifyOnce(c.UserID, models.NotificationStreakAtRisk, local.Format("2006-01-02"), title, body)
+		if err != nil {
+			log.Printf("warning: failed to send streak reminder to user %s: %v", c.UserID, err)
+			continue
+		}
+		if !delivered {
+			// Already reminded today, possibly by another replica
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d streak reminders", sent)
+	}
+	return nil
+}
+
+// streakReminderCopy builds loss-aversion wording for the reminder.
+func streakReminderCopy(streak, freezes int) (string, string) {
+	title := fmt.Sprintf("🔥 Your %d-day streak is about to break!", streak)
+	body := fmt.Sprintf("Don't lose %d days of snaps — post today's snap before midnight.", streak)
+
+	switch freezes {
+	case 0:
+		body += " You have no streak freezes left to save it."
+	case 1:
+		body += " You have 1 streak freeze left, but why spend it?"
+	default:
+		body += fmt.Sprintf(" You have %d streak freezes left, but why spend one?", freezes)
+	}
+	return title, body
+}
+
+// parseClock converts "HH:MM" into minutes after midnight, returning fallback when malformed.
+func parseClock(value string, fallback int) int {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return fallback
+	}
+	return t.Hour()*60 + t.Minute()
+}
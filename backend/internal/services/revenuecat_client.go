@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRevenueCatNotConfigured = errors.New("RevenueCat API key is not configured")
+	ErrRevenueCatNotFound      = errors.New("subscriber not found in RevenueCat")
+)
+
+// RevenueCatClient reads subscriber state from the RevenueCat REST API (v1).
+type RevenueCatClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewRevenueCatClient talks to baseURL (normally https://api.revenuecat.com; a local stub in
+// development) with a secret API key. An empty apiKey disables the client.
+func NewRevenueCatClient(baseURL, apiKey string) *RevenueCatClient {
+	return &RevenueCatClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Enabled reports whether an API key is configured.
+func (c *RevenueCatClient) Enabled() bool {
+	return c != nil && c.apiKey != ""
+}
+
+// RevenueCatSubscriber is the part of GET /v1/subscribers/{app_user_id} used for reconciliation.
+type RevenueCatSubscriber struct {
+	OriginalAppUserID string                                 `json:"original_app_user_id"`
+	Entitlements      map[string]RevenueCatEntitlement       `json:"entitlements"`
+	Subscriptions     map[string]RevenueCatSubscriptionState `json:"subscriptions"` // keyed by product ID
+}
+
+type RevenueCatEntitlement struct {
+	ProductIdentifier      string     `json:"product_identifier"`
+	ExpiresDate            *time.Time `json:"expires_date"`
+	GracePeriodExpiresDate *time.Time `json:"grace_period_expires_date"`
+	PurchaseDate           *time.Time `json:"purchase_date"`
+}
+
+type RevenueCatSubscriptionState struct {
+	PurchaseDate            *time.Time `json:"purchase_date"`
+	ExpiresDate             *time.Time `json:"expires_date"`
+	GracePeriodExpiresDate  *time.Time `json:"grace_period_expires_date"`
+	UnsubscribeDetectedAt   *time.Time `json:"unsubscribe_detected_at"`
+	BillingIssuesDetectedAt *time.Time `json:"billing_issues_detected_at"`
+	RefundedAt              *time.Time `json:"refunded_at"`
+	AutoResumeDate          *time.Time `json:"auto_resume_date"`
+	PeriodType              string     `json:"period_type"`
+	Store                   string     `json:"store"`
+	IsSandbox               bool       `json:"is_sandbox"`
+}
+
+// GetSubscriber fetches a subscriber by app user ID.
+func (c *RevenueCatClient) GetSubscriber(ctx context.Context, appUserID string) (*RevenueCatSubscriber, error) {
+	if !c.Enabled() {
+		return nil, ErrRevenueCatNotConfigured
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/subscribers/"+url.PathEscape(appUserID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("revenuecat request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrRevenueCatNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("revenuecat returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Subscriber RevenueCatSubscriber `json:"subscriber"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode revenuecat response: %w", err)
+	}
+	return &body.Subscriber, nil
+}
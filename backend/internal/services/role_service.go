@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRole = errors.New("invalid role")
+	ErrLastAdmin   = errors.New("cannot remove the last admin")
+	ErrAdminExists = errors.New("an admin already exists")
+)
+
+// RoleService reads and changes user roles. Access tokens carry the role as a claim, but
+// privileged requests re-check it here so a demotion takes effect immediately.
+type RoleService struct {
+	db *gorm.DB
+}
+
+func NewRoleService(db *gorm.DB) *RoleService {
+	return &RoleService{db: db}
+}
+
+// Role returns the user's current role.
+func (s *RoleService) Role(userID uuid.UUID) (string, error) {
+	var user models.User
+	if err := s.db.Select("id", "role").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// SetRole changes a user's role and returns the user along with their previous role, auditing
+// an actual change with audit. The last admin can't be demoted, so the admin panel can't be
+// locked out by accident.
+func (s *RoleService) SetRole(userID uuid.UUID, role string, audit *AuditEntry) (*models.User, string, error) {
+	if !models.ValidRole(role) {
+		return nil, "", ErrInvalidRole
+	}
+
+	var user models.User
+	var previous string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Serialize role changes so two admins can't demote each other at once
+		var admins []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Where("role = ?", models.RoleAdmin).Find(&admins).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		previous = user.Role
+		if user.Role == models.RoleAdmin && role != models.RoleAdmin && len(admins) <= 1 {
+			return ErrLastAdmin
+		}
+		if user.Role == role {
+			return nil
+		}
+		user.Role = role
+		if err := tx.Model(&user).Update("role", role).Error; err != nil {
+			return err
+		}
+		return audit.record(tx, map[string]string{"role": previous}, map[string]string{"role": role})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &user, previous, nil
+}
+
+// BootstrapAdmin promotes the user with the given email to admin and returns their previous
+// role, auditing the promotion with audit. Unless force is set it only works while there is no admin yet, so it can't be used to
+// take over an existing install.
+func (s *RoleService) BootstrapAdmin(email string, force bool, audit *AuditEntry) (*models.User, string, error) {
+	if !force {
+		var admins int64
+		if err := s.db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+			return nil, "", err
+		}
+		if admins > 0 {
+			return nil, "", ErrAdminExists
+		}
+	}
+
+	var user models.User
+	if err := s.db.Where("email = ?", strings.TrimSpace(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrUserNotFound
+		}
+		return nil, "", fmt.Errorf("failed to find user: %w", err)
+	}
+	if audit != nil {
+		audit.TargetID = user.ID.String()
+	}
+	return s.SetRole(user.ID, models.RoleAdmin, audit)
+}
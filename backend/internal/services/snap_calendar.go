@@ -0,0 +1,148 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Calendar day statuses.
+const (
+	CalendarActive  = "active"  // at least one snap
+	CalendarFrozen  = "frozen"  // covered by a streak freeze
+	CalendarMissed  = "missed"  // no snap or freeze since the user started snapping
+	CalendarPending = "pending" // today, not snapped yet
+	CalendarNone    = "none"    // before the first snap, or in the future
+	CalendarLocked  = "locked"  // older than the plan's calendar history
+)
+
+// MaxCalendarDays bounds a calendar request (a full leap year).
+const MaxCalendarDays = 366
+
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// GetCalendar returns one entry per local day in [from, to] (YYYY-MM-DD, inclusive) with the
+// snap count, first snap image and day status. An empty from/to defaults to the `days` days
+// ending today. Snaps are aggregated per day in SQL.
+func (s *SnapService) GetCalendar(userID uuid.UUID, from, to string, days int) (*dto.CalendarResponse, error) {
+	loc := s.userLocation(userID)
+	today := startOfDay(time.Now(), loc)
+
+	end := today
+	if to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return nil, ErrInvalidDateRange
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, 1-days)
+	if from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return nil, ErrInvalidDateRange
+		}
+		start = t
+	}
+	span := daysBetween(start, end) + 1
+	if span < 1 || span > MaxCalendarDays {
+		return nil, ErrInvalidDateRange
+	}
+
+	// Days before the plan's history window are returned as locked, without their snaps
+	limits, err := s.plans.Limits(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+	visible := start
+	var historyStart time.Time
+	if limits.CalendarHistoryDays > 0 {
+		historyStart = today.AddDate(0, 0, 1-limits.CalendarHistoryDays)
+		if visible.Before(historyStart) {
+			visible = historyStart
+		}
+	}
+
+	var rows []struct {
+		Day        string
+		Count      int
+		FirstImage string
+	}
+	err = s.db.Model(&models.Snap{}).
+		Select("to_char(snap_date AT TIME ZONE ?, 'YYYY-MM-DD') AS day, COUNT(*) AS count, "+
+			"(array_agg(image_url ORDER BY snap_date))[1] AS first_image", loc.String()).
+		Where("user_id = ? AND snap_date >= ? AND snap_date < ?", userID, visible, end.AddDate(0, 0, 1)).
+		Group("day").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate snaps: %w", err)
+	}
+
+	var frozen []string
+	err = s.db.Model(&models.FrozenDay{}).
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, visible.Format("2006-01-02"), end.Format("2006-01-02")).
+		Pluck("date", &frozen).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frozen days: %w", err)
+	}
+
+	var firstSnap sql.NullTime
+	if err := s.db.Model(&models.Snap{}).Where("user_id = ?", userID).
+		Select("MIN(snap_date)").Scan(&firstSnap).Error; err != nil {
+		return nil, fmt.Errorf("failed to get first snap: %w", err)
+	}
+	var firstDay time.Time
+	if firstSnap.Valid {
+		firstDay = startOfDay(firstSnap.Time, loc)
+	}
+
+	snapped := make(map[string]int, len(rows))
+	images := make(map[string]string, len(rows))
+	for _, r := range rows {
+		snapped[r.Day] = r.Count
+		images[r.Day] = r.FirstImage
+	}
+	frozenSet := make(map[string]bool, len(frozen))
+	for _, d := range frozen {
+		frozenSet[d] = true
+	}
+
+	calendar := &dto.CalendarResponse{
+		From:        start.Format("2006-01-02"),
+		To:          end.Format("2006-01-02"),
+		Timezone:    loc.String(),
+		Calendar:    make([]dto.CalendarDay, 0, span),
+		Dates:       []string{},
+		FrozenDates: []string{},
+	}
+	if !historyStart.IsZero() {
+		calendar.HistoryStart = historyStart.Format("2006-01-02")
+	}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		entry := dto.CalendarDay{Date: date, Count: snapped[date], ThumbnailURL: images[date]}
+		switch {
+		case day.Before(visible):
+			entry.Status = CalendarLocked
+		case entry.Count > 0:
+			entry.Status = CalendarActive
+			calendar.Dates = append(calendar.Dates, date)
+		case frozenSet[date]:
+			entry.Status = CalendarFrozen
+			calendar.FrozenDates = append(calendar.FrozenDates, date)
+		case day.Equal(today):
+			entry.Status = CalendarPending
+		case !firstSnap.Valid || day.Before(firstDay) || day.After(today):
+			entry.Status = CalendarNone
+		default:
+			entry.Status = CalendarMissed
+		}
+		calendar.Calendar = append(calendar.Calendar, entry)
+	}
+	return calendar, nil
+}
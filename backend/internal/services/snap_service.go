@@ -1,34 +1,43 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrInvalidFilter = errors.New("invalid filter")
-	ErrSnapNotFound  = errors.New("snap not found")
-	ErrNotOwner      = errors.New("you can only delete your own snaps")
+	ErrInvalidFilter   = errors.New("invalid filter")
+	ErrFilterNotInPlan = errors.New("this filter requires premium")
+	ErrSnapNotFound    = errors.New("snap not found")
+	ErrNotOwner        = errors.New("you can only delete your own snaps")
 )
 
 type SnapService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	notifications *NotificationService
+	achievements  *AchievementService
+	plans         *PlanService
 }
 
-func NewSnapService(db *gorm.DB) *SnapService {
-	return &SnapService{db: db}
+func NewSnapService(db *gorm.DB, notifications *NotificationService, achievements *AchievementService, plans *PlanService) *SnapService {
+	return &SnapService{db: db, notifications: notifications, achievements: achievements, plans: plans}
 }
 
 // CreateSnap creates a new snap and updates the user's streak.
-func (s *SnapService) CreateSnap(userID uuid.UUID, imageURL string, caption string, filter string) (*models.Snap, error) {
+// promptID is optional and ties the snap to the user's prompt for today.
+func (s *SnapService) CreateSnap(userID uuid.UUID, imageURL string, caption string, filter string, promptID *uuid.UUID) (*models.Snap, error) {
 	// Validate filter
 	validFilter := false
-	for _, f := range models.SnapFilters {
+	for _, f := range models.AllSnapFilters() {
 		if f == filter {
 			validFilter = true
 			break
@@ -38,75 +47,150 @@ func (s *SnapService) CreateSnap(userID uuid.UUID, imageURL string, caption stri
 		return nil, ErrInvalidFilter
 	}
 
+	limits, err := s.plans.Limits(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+	if !limits.AllowsFilter(filter) {
+		return nil, ErrFilterNotInPlan
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "timezone").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now()
+	if promptID != nil {
+		if err := NewPromptService(s.db).CheckTodayPrompt(*promptID, startOfDay(now, user.Location())); err != nil {
+			return nil, err
+		}
+	}
+	windowStatus := SnapWindowFor(now, user.Location()).Status(now)
+
 	snap := models.Snap{
-		ID:       uuid.New(),
-		UserID:   userID,
-		ImageURL: imageURL,
-		Caption:  caption,
-		Filter:   filter,
-		SnapDate: time.Now(),
+		ID:           uuid.New(),
+		UserID:       userID,
+		ImageURL:     imageURL,
+		Caption:      caption,
+		Filter:       filter,
+		SnapDate:     now,
+		WindowStatus: windowStatus,
+		PromptID:     promptID,
 	}
 
-	if err := s.db.Create(&snap).Error; err != nil {
-		return nil, fmt.Errorf("failed to create snap: %w", err)
+	// Insert the snap and update the streak atomically; the streak row lock serializes
+	// concurrent uploads from the same user
+	var streak *models.SnapStreak
+	var frozen int
+	var ended *models.StreakEpoch
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&snap).Error; err != nil {
+			return fmt.Errorf("failed to create snap: %w", err)
+		}
+		var err error
+		streak, frozen, ended, err = updateStreak(tx, userID, user.Location(), now, limits.MaxFreezes)
+		if err != nil {
+			return fmt.Errorf("failed to update streak: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Update streak after successful snap creation
-	if err := s.updateStreak(userID); err != nil {
-		// Log but don't fail the snap creation
-		fmt.Printf("warning: failed to update streak for user %s: %v\n", userID, err)
+	s.evaluateSnapAchievements(streak, frozen, promptID != nil)
+	if ended != nil {
+		s.notifyStreakBroken(ended)
 	}
 
 	return &snap, nil
 }
 
-// updateStreak updates the streak record for the user based on when they last snapped.
-func (s *SnapService) updateStreak(userID uuid.UUID) error {
-	var streak models.SnapStreak
-	err := s.db.Where("user_id = ?", userID).First(&streak).Error
-
-	now := time.Now()
-	today := now.Truncate(24 * time.Hour)
+// evaluateSnapAchievements fires the achievement events for a committed snap.
+func (s *SnapService) evaluateSnapAchievements(streak *models.SnapStreak, frozen int, answeredPrompt bool) {
+	if s.achievements == nil {
+		return
+	}
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// Create new streak record
-		streak = models.SnapStreak{
-			ID:            uuid.New(),
-			UserID:        userID,
-			CurrentStreak: 1,
-			LongestStreak: 1,
-			TotalSnaps:    1,
-			LastSnapDate:  now,
+	events := []AchievementEvent{
+		{UserID: streak.UserID, Type: models.EventSnapCreated, Value: streak.TotalSnaps},
+		{UserID: streak.UserID, Type: models.EventStreakExtended, Value: streak.CurrentStreak},
+	}
+	if frozen > 0 {
+		events = append(events, AchievementEvent{UserID: streak.UserID, Type: models.EventFreezeUsed, Value: streak.FreezesUsed})
+	}
+	if answeredPrompt {
+		var answered int64
+		if err := s.db.Model(&models.Snap{}).
+			Where("user_id = ? AND prompt_id IS NOT NULL", streak.UserID).
+			Count(&answered).Error; err != nil {
+			log.Printf("warning: failed to count prompt snaps for user %s: %v", streak.UserID, err)
+		} else {
+			events = append(events, AchievementEvent{UserID: streak.UserID, Type: models.EventPromptCompleted, Value: int(answered)})
 		}
-		return s.db.Create(&streak).Error
-	} else if err != nil {
-		return fmt.Errorf("failed to find streak: %w", err)
+	}
+	s.achievements.Evaluate(events...)
+}
+
+// evaluateFreezeAchievements fires the freeze event after frozen days were committed outside CreateSnap.
+func (s *SnapService) evaluateFreezeAchievements(streak *models.SnapStreak, frozen int) {
+	if s.achievements == nil || frozen == 0 {
+		return
+	}
+	s.achievements.Evaluate(AchievementEvent{UserID: streak.UserID, Type: models.EventFreezeUsed, Value: streak.FreezesUsed})
+}
+
+// updateStreak updates the streak record for the user based on when they last snapped.
+// Days are calendar days in the user's time zone; missed days are handled by settleStreak.
+// It must run inside tx, which holds the streak row lock until commit. Milestone freezes stop at
+// maxFreezes. Returns the saved streak, how many missed days were frozen and the streak that
+// ended, if the gap broke it.
+func updateStreak(tx *gorm.DB, userID uuid.UUID, loc *time.Location, now time.Time, maxFreezes int) (*models.SnapStreak, int, *models.StreakEpoch, error) {
+	streak, err := lockStreak(tx, userID)
+	if err != nil {
+		return nil, 0, nil, err
 	}
 
-	lastSnapDay := streak.LastSnapDate.Truncate(24 * time.Hour)
+	today := startOfDay(now, loc)
 
-	if lastSnapDay.Equal(today) {
-		// Already snapped today, just increment total
+	// First snap ever (the row may also have been created earlier, e.g. by a freeze purchase)
+	if streak.LastSnapDate.IsZero() {
+		streak.CurrentStreak = 1
+		streak.StartDate = today.Format("2006-01-02")
+		if streak.LongestStreak < 1 {
+			streak.LongestStreak = 1
+		}
 		streak.TotalSnaps++
 		streak.LastSnapDate = now
-		return s.db.Save(&streak).Error
-	}
-
-	yesterday := today.Add(-24 * time.Hour)
-	if lastSnapDay.Equal(yesterday) {
-		// Consecutive day, increment streak
-		streak.CurrentStreak++
-	} else {
-		// Streak broken - check for freeze availability
-		if streak.FreezesAvailable > 0 {
-			// Use a freeze to protect the streak
-			streak.FreezesAvailable--
-			streak.FreezesUsed++
-			streak.LastFreezeDate = time.Now()
-			// Keep CurrentStreak unchanged - the missed day is forgiven
-		} else {
-			// No freezes available - reset streak to 1
-			streak.CurrentStreak = 1
+		return streak, 0, nil, tx.Save(streak).Error
+	}
+
+	if !streak.LastSnapDate.Before(today) {
+		// Already snapped today, just increment total. A concurrent upload with a later
+		// timestamp may have committed first, so never move LastSnapDate backwards.
+		streak.TotalSnaps++
+		if now.After(streak.LastSnapDate) {
+			streak.LastSnapDate = now
+		}
+		return streak, 0, nil, tx.Save(streak).Error
+	}
+
+	// Cover or break any days missed since the last snap, then count today
+	frozen, ended, err := settleStreak(tx, streak, loc, now)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if streak.CurrentStreak == 0 {
+		streak.StartDate = today.Format("2006-01-02")
+	}
+	streak.CurrentStreak++
+
+	// Every 30-day milestone earns a freeze
+	if streak.CurrentStreak%FreezeMilestoneDays == 0 {
+		ref := fmt.Sprintf("milestone:%s:%d", today.Format("2006-01-02"), streak.CurrentStreak)
+		if _, err := grantFreezes(tx, streak, 1, models.FreezeSourceMilestone, &ref, maxFreezes); err != nil {
+			return nil, 0, nil, err
 		}
 	}
 
@@ -117,17 +201,172 @@ func (s *SnapService) updateStreak(userID uuid.UUID) error {
 	streak.TotalSnaps++
 	streak.LastSnapDate = now
 
-	return s.db.Save(&streak).Error
+	return streak, frozen, ended, tx.Save(streak).Error
+}
+
+// settleStreak brings a live streak up to date as of now: every local day missed since the
+// last snapped or frozen day costs one freeze. If there aren't enough freezes to cover the
+// whole gap, none are spent and the streak is broken (CurrentStreak = 0) and stored as a
+// StreakEpoch. The caller persists streak. Returns how many days were frozen and the epoch
+// that ended, if any.
+func settleStreak(tx *gorm.DB, streak *models.SnapStreak, loc *time.Location, now time.Time) (int, *models.StreakEpoch, error) {
+	if streak.CurrentStreak == 0 || streak.LastSnapDate.IsZero() {
+		return 0, nil, nil
+	}
+
+	today := startOfDay(now, loc)
+	lastCovered := startOfDay(streak.LastSnapDate, loc)
+
+	var lastFrozen *string
+	if err := tx.Model(&models.FrozenDay{}).
+		Where("user_id = ?", streak.UserID).
+		Select("MAX(date)").
+		Scan(&lastFrozen).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load frozen days: %w", err)
+	}
+	if lastFrozen != nil {
+		if day, err := time.ParseInLocation("2006-01-02", *lastFrozen, loc); err == nil && day.After(lastCovered) {
+			lastCovered = day
+		}
+	}
+
+	missed := daysBetween(lastCovered, today) - 1
+	if missed <= 0 {
+		return 0, nil, nil
+	}
+
+	if missed <= streak.FreezesAvailable {
+		frozen := make([]string, missed)
+		for i := range frozen {
+			frozen[i] = today.AddDate(0, 0, i-missed).Format("2006-01-02")
+		}
+		if err := useFreezes(tx, streak, frozen); err != nil {
+			return 0, nil, err
+		}
+		return missed, nil, nil
+	}
+
+	startDate := streak.StartDate
+	if startDate == "" {
+		// Streaks started before start dates were tracked: approximate from the length
+		startDate = lastCovered.AddDate(0, 0, 1-streak.CurrentStreak).Format("2006-01-02")
+	}
+	epoch := models.StreakEpoch{
+		ID:        uuid.New(),
+		UserID:    streak.UserID,
+		StartDate: startDate,
+		EndDate:   lastCovered.Format("2006-01-02"),
+		Length:    streak.CurrentStreak,
+	}
+	if err := tx.Create(&epoch).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to record streak history: %w", err)
+	}
+
+	streak.CurrentStreak = 0
+	streak.StartDate = ""
+	return 0, &epoch, nil
+}
+
+// RolloverStreaks proactively settles every live streak whose owner hasn't snapped in the last
+// 24 hours, so streaks break (or consume freezes) when the user's local day ends rather than the
+// next time they snap. Users whose streak broke are notified.
+func (s *SnapService) RolloverStreaks(ctx context.Context, now time.Time) error {
+	var candidates []struct {
+		UserID   uuid.UUID
+		Timezone string
+	}
+	err := s.db.WithContext(ctx).
+		Table("snap_streaks").
+		Select("snap_streaks.user_id, users.timezone").
+		Joins("JOIN users ON users.id = snap_streaks.user_id AND users.deleted_at IS NULL").
+		Where("snap_streaks.current_streak > 0 AND snap_streaks.last_snap_date < ?", now.Add(-24*time.Hour)).
+		Scan(&candidates).Error
+	if err != nil {
+		return fmt.Errorf("failed to load streaks for rollover: %w", err)
+	}
+
+	broken := 0
+	for _, c := range candidates {
+		loc := (&models.User{Timezone: c.Timezone}).Location()
+
+		var streak models.SnapStreak
+		var frozen int
+		var ended *models.StreakEpoch
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_id = ?", c.UserID).First(&streak).Error; err != nil {
+				return err
+			}
+
+			var err error
+			frozen, ended, err = settleStreak(tx, &streak, loc, now)
+			if err != nil || (frozen == 0 && ended == nil) {
+				return err
+			}
+			return tx.Save(&streak).Error
+		})
+		if err != nil {
+			log.Printf("warning: failed to roll over streak for user %s: %v", c.UserID, err)
+			continue
+		}
+
+		s.evaluateFreezeAchievements(&streak, frozen)
+
+		if ended != nil {
+			broken++
+			s.notifyStreakBroken(ended)
+		}
+	}
+
+	if broken > 0 {
+		log.Printf("Rolled over streaks: %d broken", broken)
+	}
+	return nil
 }
 
-// GetUserSnaps returns paginated snaps for a user.
-func (s *SnapService) GetUserSnaps(userID uuid.UUID, limit int, offset int) ([]models.Snap, int64, error) {
+// notifyStreakBroken tells the user their streak ended. Whichever of CreateSnap, GetStreak and
+// RolloverStreaks settles the break first sends it; NotifyOnce keys it by the streak's end date.
+func (s *SnapService) notifyStreakBroken(epoch *models.StreakEpoch) {
+	if s.notifications == nil {
+		return
+	}
+
+	title := fmt.Sprintf("💔 Your %d-day streak ended", epoch.Length)
+	body := "Every streak starts with day one. Snap today to start a new one!"
+	if _, err := s.notifications.NotifyOnce(epoch.UserID, models.NotificationStreakBroken, epoch.EndDate, title, body); err != nil {
+		log.Printf("warning: failed to send streak broken notification to user %s: %v", epoch.UserID, err)
+	}
+}
+
+// userLocation returns the time zone of the user, or UTC if the user can't be loaded.
+func (s *SnapService) userLocation(userID uuid.UUID) *time.Location {
+	var user models.User
+	if err := s.db.Select("id", "timezone").First(&user, "id = ?", userID).Error; err != nil {
+		return time.UTC
+	}
+	return user.Location()
+}
+
+// daysBetween returns the number of calendar days from day a to day b (both local midnights).
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+// GetUserSnaps returns paginated snaps for a user, optionally only those answering a prompt.
+func (s *SnapService) GetUserSnaps(userID uuid.UUID, promptID *uuid.UUID, limit int, offset int) ([]models.Snap, int64, error) {
 	var snaps []models.Snap
 	var total int64
 
-	s.db.Model(&models.Snap{}).Where("user_id = ?", userID).Count(&total)
+	query := s.db.Model(&models.Snap{}).Where("user_id = ?", userID)
+	if promptID != nil {
+		query = query.Where("prompt_id = ?", *promptID)
+	}
+
+	query.Count(&total)
 
-	err := s.db.Where("user_id = ?", userID).
+	err := query.
 		Order("snap_date DESC").
 		Limit(limit).
 		Offset(offset).
@@ -136,10 +375,28 @@ func (s *SnapService) GetUserSnaps(userID uuid.UUID, limit int, offset int) ([]m
 	return snaps, total, err
 }
 
-// GetStreak returns the streak record for a user.
+// GetStreak returns the streak record for a user. The streak is settled at read time, so
+// missed days are reflected even if the rollover job hasn't processed this user yet; a streak
+// broken here is notified just as the rollover would.
 func (s *SnapService) GetStreak(userID uuid.UUID) (*models.SnapStreak, error) {
+	loc := s.userLocation(userID)
+
 	var streak models.SnapStreak
-	err := s.db.Where("user_id = ?", userID).First(&streak).Error
+	var frozen int
+	var ended *models.StreakEpoch
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&streak).Error; err != nil {
+			return err
+		}
+
+		var err error
+		frozen, ended, err = settleStreak(tx, &streak, loc, time.Now())
+		if err != nil || (frozen == 0 && ended == nil) {
+			return err
+		}
+		return tx.Save(&streak).Error
+	})
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return &models.SnapStreak{
 			UserID:        userID,
@@ -151,12 +408,16 @@ func (s *SnapService) GetStreak(userID uuid.UUID) (*models.SnapStreak, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get streak: %w", err)
 	}
+	s.evaluateFreezeAchievements(&streak, frozen)
+	if ended != nil {
+		s.notifyStreakBroken(ended)
+	}
 	return &streak, nil
 }
 
-// GetTodaySnap checks if the user has already posted a snap today.
+// GetTodaySnap checks if the user has already posted a snap today (in their time zone).
 func (s *SnapService) GetTodaySnap(userID uuid.UUID) (*models.Snap, error) {
-	today := time.Now().Truncate(24 * time.Hour)
+	today := startOfDay(time.Now(), s.userLocation(userID))
 	var snap models.Snap
 	err := s.db.Where("user_id = ? AND snap_date >= ?", userID, today).
 		Order("snap_date DESC").
@@ -170,16 +431,23 @@ func (s *SnapService) GetTodaySnap(userID uuid.UUID) (*models.Snap, error) {
 	return &snap, nil
 }
 
-// DeleteSnap soft-deletes a snap only if owned by the user.
+// DeleteSnap soft-deletes a snap only if owned by the user, then rebuilds the streak from the
+// remaining history so totals (and the streak itself, if that day is now empty) stay correct.
 func (s *SnapService) DeleteSnap(userID uuid.UUID, snapID uuid.UUID) error {
-	result := s.db.Where("id = ? AND user_id = ?", snapID, userID).Delete(&models.Snap{})
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete snap: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return ErrSnapNotFound
-	}
-	return nil
+	loc := s.userLocation(userID)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", snapID, userID).Delete(&models.Snap{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete snap: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrSnapNotFound
+		}
+
+		_, err := rebuildStreak(tx, userID, loc, time.Now(), true)
+		return err
+	})
 }
 
 // LikeSnap increments the like count for a snap.
@@ -196,56 +464,33 @@ func (s *SnapService) LikeSnap(snapID uuid.UUID) error {
 	return nil
 }
 
-// AddStreakFreeze adds one streak freeze to the user's available freezes.
-// Maximum of 3 freezes can be stored at once.
-func (s *SnapService) AddStreakFreeze(userID uuid.UUID) error {
-	var streak models.SnapStreak
-
-	err := s.db.Where("user_id = ?", userID).First(&streak).Error
+// GetStreakHistory returns the current streak and every completed one, most recent first.
+// The longest streak matches GET /snaps/streak: the summary row's LongestStreak, which also
+// covers streaks that ended before history was kept, raised by any longer streak in the history.
+func (s *SnapService) GetStreakHistory(userID uuid.UUID) (*dto.StreakHistoryResponse, error) {
+	streak, err := s.GetStreak(userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("no streak record found for user")
-		}
-		return err
-	}
-
-	if streak.FreezesAvailable >= 3 {
-		return errors.New("maximum freezes reached (3)")
+		return nil, err
 	}
 
-	streak.FreezesAvailable++
-
-	return s.db.Save(&streak).Error
-}
-
-// GetSnapDates retrieves all snap dates for a user within the specified number of days.
-// Used for generating the activity heatmap calendar.
-func (s *SnapService) GetSnapDates(userID uuid.UUID, days int) ([]string, error) {
-	if days > 90 {
-		days = 90
+	var epochs []models.StreakEpoch
+	if err := s.db.Where("user_id = ?", userID).Order("end_date DESC").Find(&epochs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get streak history: %w", err)
 	}
-	if days < 7 {
-		days = 7
-	}
-
-	since := time.Now().AddDate(0, 0, -days)
 
-	var snaps []models.Snap
-	err := s.db.Where("user_id = ? AND snap_date >= ?", userID, since).
-		Select("snap_date").
-		Order("snap_date ASC").
-		Find(&snaps).Error
-
-	if err != nil {
-		return nil, err
+	history := &dto.StreakHistoryResponse{
+		CurrentStreak:    streak.CurrentStreak,
+		CurrentStartDate: streak.StartDate,
+		LongestStreak:    max(streak.LongestStreak, streak.CurrentStreak),
+		PastStreaks:      make([]dto.StreakEpochResponse, len(epochs)),
 	}
-
-	dates := make([]string, len(snaps))
-	for i, snap := range snaps {
-		dates[i] = snap.SnapDate.Format("2006-01-02")
+	for i, e := range epochs {
+		history.PastStreaks[i] = dto.StreakEpochResponse{StartDate: e.StartDate, EndDate: e.EndDate, Length: e.Length}
+		if e.Length > history.LongestStreak {
+			history.LongestStreak = e.Length
+		}
 	}
-
-	return dates, nil
+	return history, nil
 }
 
 // GetStreakWithFreezes retrieves the streak data including freeze information.
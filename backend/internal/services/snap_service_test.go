@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestCreateSnapConcurrent(t *testing.T) {
+	const uploads = 20
+
+	tests := []struct {
+		name       string
+		seed       *models.SnapStreak // existing streak, nil for a first snap
+		wantStreak int
+		wantTotal  int
+	}{
+		{
+			name:       "first day",
+			wantStreak: 1,
+			wantTotal:  uploads,
+		},
+		{
+			name: "continuing from yesterday",
+			seed: &models.SnapStreak{
+				CurrentStreak: 5,
+				LongestStreak: 5,
+				TotalSnaps:    5,
+				LastSnapDate:  time.Now().UTC().AddDate(0, 0, -1),
+				StartDate:     time.Now().UTC().AddDate(0, 0, -5).Format("2006-01-02"),
+			},
+			wantStreak: 6,
+			wantTotal:  5 + uploads,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			userID := uuid.New()
+			createTestUser(t, db, userID)
+			if tt.seed != nil {
+				tt.seed.ID = uuid.New()
+				tt.seed.UserID = userID
+				if err := db.Create(tt.seed).Error; err != nil {
+					t.Fatalf("seed streak: %v", err)
+				}
+			}
+			snaps := NewSnapService(db, nil, nil, nil)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, uploads)
+			for i := 0; i < uploads; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := snaps.CreateSnap(userID, "/uploads/snaps/test.jpg", "", "none", nil); err != nil {
+						errs <- err
+					}
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Errorf("CreateSnap: %v", err)
+			}
+
+			var stored int64
+			db.Model(&models.Snap{}).Where("user_id = ?", userID).Count(&stored)
+			if stored != uploads {
+				t.Errorf("stored %d snaps, want %d", stored, uploads)
+			}
+
+			var streak models.SnapStreak
+			if err := db.Where("user_id = ?", userID).First(&streak).Error; err != nil {
+				t.Fatalf("load streak: %v", err)
+			}
+			if streak.TotalSnaps != tt.wantTotal {
+				t.Errorf("TotalSnaps = %d, want %d", streak.TotalSnaps, tt.wantTotal)
+			}
+			if streak.CurrentStreak != tt.wantStreak {
+				t.Errorf("CurrentStreak = %d, want %d", streak.CurrentStreak, tt.wantStreak)
+			}
+			if streak.LongestStreak != tt.wantStreak {
+				t.Errorf("LongestStreak = %d, want %d", streak.LongestStreak, tt.wantStreak)
+			}
+		})
+	}
+}
+
+func TestStreakBrokenNotification(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name   string
+		settle func(s *SnapService, userID uuid.UUID) error
+	}{
+		{
+			name: "read before the rollover",
+			settle: func(s *SnapService, userID uuid.UUID) error {
+				_, err := s.GetStreak(userID)
+				return err
+			},
+		},
+		{
+			name: "snap before the rollover",
+			settle: func(s *SnapService, userID uuid.UUID) error {
+				_, err := s.CreateSnap(userID, "/uploads/snaps/test.jpg", "", "none", nil)
+				return err
+			},
+		},
+		{
+			name: "rollover",
+			settle: func(s *SnapService, _ uuid.UUID) error {
+				return s.RolloverStreaks(context.Background(), now)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			userID := uuid.New()
+			createTestUser(t, db, userID)
+			if err := db.Create(&models.SnapStreak{
+				ID:            uuid.New(),
+				UserID:        userID,
+				CurrentStreak: 5,
+				LongestStreak: 5,
+				TotalSnaps:    5,
+				LastSnapDate:  now.AddDate(0, 0, -3),
+				StartDate:     now.AddDate(0, 0, -7).Format("2006-01-02"),
+			}).Error; err != nil {
+				t.Fatalf("seed streak: %v", err)
+			}
+			snaps := NewSnapService(db, NewNotificationService(db), nil, nil)
+
+			if err := tt.settle(snaps, userID); err != nil {
+				t.Fatalf("settle: %v", err)
+			}
+			// A later rollover finds nothing left to break and must not notify again
+			if err := snaps.RolloverStreaks(context.Background(), now); err != nil {
+				t.Fatalf("rollover: %v", err)
+			}
+
+			var sent int64
+			db.Model(&models.Notification{}).
+				Where("user_id = ? AND type = ?", userID, models.NotificationStreakBroken).
+				Count(&sent)
+			if sent != 1 {
+				t.Errorf("sent %d streak broken notifications, want 1", sent)
+			}
+		})
+	}
+}
+
+func TestCreateSnapPromptMustBeToday(t *testing.T) {
+	db := testDB(t)
+	userID := uuid.New()
+	createTestUser(t, db, userID)
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	today := startOfDay(time.Now(), user.Location())
+
+	prompts := map[string]uuid.UUID{}
+	for name, day := range map[string]time.Time{"today": today, "yesterday": today.AddDate(0, 0, -1), "tomorrow": today.AddDate(0, 0, 1)} {
+		date := day.Format("2006-01-02")
+		prompt := models.Prompt{ID: uuid.New(), Text: "Show your " + name, Locale: defaultPromptLocale, ScheduledDate: &date}
+		if err := db.Create(&prompt).Error; err != nil {
+			t.Fatalf("seed prompt: %v", err)
+		}
+		prompts[name] = prompt.ID
+	}
+
+	tests := []struct {
+		name     string
+		promptID uuid.UUID
+		wantErr  error
+	}{
+		{"today's prompt", prompts["today"], nil},
+		{"yesterday's prompt", prompts["yesterday"], ErrPromptNotToday},
+		{"tomorrow's prompt", prompts["tomorrow"], ErrPromptNotToday},
+		{"unknown prompt", uuid.New(), ErrPromptNotFound},
+	}
+	snaps := NewSnapService(db, nil, nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promptID := tt.promptID
+			_, err := snaps.CreateSnap(userID, "/uploads/snaps/test.jpg", "", "none", &promptID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateSnap: err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	statsMonths    = 12     // calendar months of completion rates reported
+	statsCacheSize = 10_000 // cached users before the cache is reset
+)
+
+// StatsService computes personal snap statistics with SQL aggregates in the user's time zone.
+// Results are cached in memory until the user's snaps change or their local day rolls over.
+type StatsService struct {
+	db    *gorm.DB
+	mu    sync.Mutex
+	cache map[uuid.UUID]statsCacheEntry
+}
+
+// statsCacheEntry is valid while the streak summary (updated on every snap create/delete),
+// the user's time zone and their local date still match.
+type statsCacheEntry struct {
+	totalSnaps   int
+	lastSnapDate time.Time
+	localDay     string // "YYYY-MM-DD Zone"
+	stats        *dto.StatsResponse
+}
+
+func NewStatsService(db *gorm.DB) *StatsService {
+	return &StatsService{db: db, cache: make(map[uuid.UUID]statsCacheEntry)}
+}
+
+// GetStats returns the user's statistics, from cache when nothing has changed.
+func (s *StatsService) GetStats(userID uuid.UUID) (*dto.StatsResponse, error) {
+	var user models.User
+	if err := s.db.Select("id", "timezone").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+	loc := user.Location()
+	now := time.Now()
+	today := startOfDay(now, loc)
+
+	var streak models.SnapStreak
+	if err := s.db.Where("user_id = ?", userID).Limit(1).Find(&streak).Error; err != nil {
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+
+	key := statsCacheEntry{totalSnaps: streak.TotalSnaps, lastSnapDate: streak.LastSnapDate, localDay: today.Format("2006-01-02") + " " + loc.String()}
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+	if ok && cached.totalSnaps == key.totalSnaps && cached.lastSnapDate.Equal(key.lastSnapDate) && cached.localDay == key.localDay {
+		return cached.stats, nil
+	}
+
+	stats, err := s.computeStats(userID, loc, today)
+	if err != nil {
+		return nil, err
+	}
+
+	key.stats = stats
+	s.mu.Lock()
+	if len(s.cache) >= statsCacheSize {
+		s.cache = make(map[uuid.UUID]statsCacheEntry)
+	}
+	s.cache[userID] = key
+	s.mu.Unlock()
+	return stats, nil
+}
+
+func (s *StatsService) computeStats(userID uuid.UUID, loc *time.Location, today time.Time) (*dto.StatsResponse, error) {
+	tz := loc.String()
+	stats := &dto.StatsResponse{
+		Timezone:          tz,
+		SnapsPerWeekday:   make([]dto.WeekdayCount, 7),
+		FilterCounts:      make(map[string]int, len(models.SnapFilters)+len(models.PremiumSnapFilters)),
+		MonthlyCompletion: []dto.MonthCompletion{},
+		GeneratedAt:       time.Now(),
+	}
+	for i := range stats.SnapsPerWeekday {
+		stats.SnapsPerWeekday[i].Weekday = time.Weekday((i + 1) % 7).String() // Monday first
+	}
+	for _, f := range models.AllSnapFilters() {
+		stats.FilterCounts[f] = 0
+	}
+
+	// Snaps per ISO weekday (1 = Monday) and the average local posting hour
+	var weekdays []struct {
+		Dow   int
+		Count int
+	}
+	err := s.db.Raw(`SELECT EXTRACT(ISODOW FROM snap_date AT TIME ZONE ?)::int AS dow, COUNT(*) AS count
+		FROM snaps WHERE user_id = ? AND deleted_at IS NULL
+		GROUP BY dow`, tz, userID).Scan(&weekdays).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate weekdays: %w", err)
+	}
+	for _, w := range weekdays {
+		stats.SnapsPerWeekday[w.Dow-1].Count = w.Count
+		stats.TotalSnaps += w.Count
+	}
+
+	var avgHour sql.NullFloat64
+	err = s.db.Raw(`SELECT AVG(EXTRACT(HOUR FROM snap_date AT TIME ZONE ?) + EXTRACT(MINUTE FROM snap_date AT TIME ZONE ?) / 60.0)
+		FROM snaps WHERE user_id = ? AND deleted_at IS NULL`, tz, tz, userID).Scan(&avgHour).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate posting hour: %w", err)
+	}
+	if avgHour.Valid {
+		rounded := math.Round(avgHour.Float64*10) / 10
+		stats.AverageHour = &rounded
+	}
+
+	// Filter usage; the most used filter ignores "none"
+	var filters []struct {
+		Filter string
+		Count  int
+	}
+	err = s.db.Raw(`SELECT filter, COUNT(*) AS count
+		FROM snaps WHERE user_id = ? AND deleted_at IS NULL
+		GROUP BY filter ORDER BY count DESC, filter`, userID).Scan(&filters).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate filters: %w", err)
+	}
+	for _, f := range filters {
+		if _, known := stats.FilterCounts[f.Filter]; !known {
+			continue
+		}
+		stats.FilterCounts[f.Filter] = f.Count
+		if stats.MostUsedFilter == "" && f.Filter != "none" {
+			stats.MostUsedFilter = f.Filter
+		}
+	}
+
+	// Distinct snapped days per month, over the last statsMonths months
+	firstMonth := time.Date(today.Year(), today.Month()-statsMonths+1, 1, 0, 0, 0, 0, loc)
+	var months []struct {
+		Month    string
+		SnapDays int
+		FirstDay time.Time
+	}
+	err = s.db.Raw(`SELECT to_char(local_date, 'YYYY-MM') AS month, COUNT(DISTINCT local_date) AS snap_days, MIN(local_date) AS first_day
+		FROM (SELECT (snap_date AT TIME ZONE ?)::date AS local_date
+			FROM snaps WHERE user_id = ? AND deleted_at IS NULL AND snap_date >= ?) local
+		GROUP BY month ORDER BY month`, tz, userID, firstMonth).Scan(&months).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate months: %w", err)
+	}
+	var firstSnap sql.NullTime
+	if err := s.db.Model(&models.Snap{}).Where("user_id = ?", userID).
+		Select("MIN(snap_date)").Scan(&firstSnap).Error; err != nil {
+		return nil, fmt.Errorf("failed to get first snap: %w", err)
+	}
+	snapDays := make(map[string]int, len(months))
+	for _, m := range months {
+		snapDays[m.Month] = m.SnapDays
+	}
+	if firstSnap.Valid {
+		firstDay := startOfDay(firstSnap.Time, loc)
+		for month := firstMonth; !month.After(today); month = month.AddDate(0, 1, 0) {
+			// Only count days the user could have snapped: from their first snap up to today
+			start, end := month, month.AddDate(0, 1, -1)
+			if start.Before(firstDay) {
+				start = firstDay
+			}
+			if end.After(today) {
+				end = today
+			}
+			if start.After(end) {
+				continue
+			}
+			key := month.Format("2006-01")
+			days := daysBetween(start, end) + 1
+			stats.MonthlyCompletion = append(stats.MonthlyCompletion, dto.MonthCompletion{
+				Month:    key,
+				SnapDays: snapDays[key],
+				Days:     days,
+				Rate:     math.Round(float64(snapDays[key])/float64(days)*1000) / 1000,
+			})
+		}
+	}
+
+	// Longest run of days without a snap between two snapped days
+	var gap struct {
+		Prev time.Time
+		Day  time.Time
+	}
+	err = s.db.Raw(`SELECT prev, day FROM (
+			SELECT day, LAG(day) OVER (ORDER BY day) AS prev
+			FROM (SELECT DISTINCT (snap_date AT TIME ZONE ?)::date AS day
+				FROM snaps WHERE user_id = ? AND deleted_at IS NULL) days
+		) gaps
+		WHERE prev IS NOT NULL AND day - prev > 1
+		ORDER BY day - prev DESC, day DESC LIMIT 1`, tz, userID).Scan(&gap).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find longest gap: %w", err)
+	}
+	if !gap.Prev.IsZero() {
+		stats.LongestGap = &dto.SnapGap{
+			Days: daysBetween(gap.Prev, gap.Day) - 1,
+			From: gap.Prev.Format("2006-01-02"),
+			To:   gap.Day.Format("2006-01-02"),
+		}
+	}
+
+	return stats, nil
+}
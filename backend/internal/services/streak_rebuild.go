@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RebuildStreak recomputes a user's streak summary from their snap and freeze history.
+// With dryRun the stored record is left untouched and only the diff is returned. A saved change
+// is audited with a copy of audit targeting the user.
+func (s *SnapService) RebuildStreak(userID uuid.UUID, dryRun bool, audit *AuditEntry) (*dto.StreakRebuildResult, error) {
+	loc := s.userLocation(userID)
+
+	var result *dto.StreakRebuildResult
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = rebuildStreak(tx, userID, loc, time.Now(), !dryRun)
+		if err != nil || !result.Applied || audit == nil {
+			return err
+		}
+		entry := *audit
+		entry.TargetID = result.UserID
+		return entry.record(tx, result.Before, result.After)
+	})
+	return result, err
+}
+
+// RebuildAllStreaks runs RebuildStreak for every user with snaps or a streak record.
+// Each user is rebuilt (and audited) in its own transaction; the report lists only users whose
+// record differed.
+func (s *SnapService) RebuildAllStreaks(dryRun bool, audit *AuditEntry) (*dto.StreakRebuildReport, error) {
+	var userIDs []uuid.UUID
+	if err := s.db.Raw(`SELECT user_id FROM snaps WHERE deleted_at IS NULL
+		UNION SELECT user_id FROM snap_streaks`).Scan(&userIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	report := &dto.StreakRebuildReport{DryRun: dryRun, Changed: []dto.StreakRebuildResult{}}
+	for _, userID := range userIDs {
+		result, err := s.RebuildStreak(userID, dryRun, audit)
+		if err != nil {
+			return report, fmt.Errorf("failed to rebuild streak for user %s: %w", userID, err)
+		}
+		report.Checked++
+		if result.Changed {
+			report.Changed = append(report.Changed, *result)
+		}
+	}
+	return report, nil
+}
+
+// rebuildStreak replays the user's history inside tx and, when apply is set, saves the result.
+func rebuildStreak(tx *gorm.DB, userID uuid.UUID, loc *time.Location, now time.Time, apply bool) (*dto.StreakRebuildResult, error) {
+	var streak models.SnapStreak
+	if apply {
+		locked, err := lockStreak(tx, userID)
+		if err != nil {
+			return nil, err
+		}
+		streak = *locked
+	} else if err := tx.Where("user_id = ?", userID).First(&streak).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load streak: %w", err)
+	}
+
+	var snapDates []time.Time
+	if err := tx.Model(&models.Snap{}).
+		Where("user_id = ?", userID).
+		Order("snap_date ASC").
+		Pluck("snap_date", &snapDates).Error; err != nil {
+		return nil, fmt.Errorf("failed to load snaps: %w", err)
+	}
+
+	var frozenDates []string
+	if err := tx.Model(&models.FrozenDay{}).
+		Where("user_id = ?", userID).
+		Pluck("date", &frozenDates).Error; err != nil {
+		return nil, fmt.Errorf("failed to load frozen days: %w", err)
+	}
+
+	var storedEpochs []models.StreakEpoch
+	if err := tx.Where("user_id = ?", userID).Order("end_date ASC").Find(&storedEpochs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load streak history: %w", err)
+	}
+
+	rebuilt := streak
+	epochs := replayStreak(&rebuilt, snapDates, frozenDates, loc, now)
+
+	result := &dto.StreakRebuildResult{
+		UserID: userID.String(),
+		Before: toStreakSummary(&streak, storedEpochs),
+		After:  toStreakSummary(&rebuilt, epochs),
+	}
+	result.Changed = result.Before != result.After
+
+	if apply && result.Changed {
+		if err := tx.Save(&rebuilt).Error; err != nil {
+			return nil, fmt.Errorf("failed to save rebuilt streak: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.StreakEpoch{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to clear streak history: %w", err)
+		}
+		if len(epochs) > 0 {
+			if err := tx.Create(&epochs).Error; err != nil {
+				return nil, fmt.Errorf("failed to save streak history: %w", err)
+			}
+		}
+		result.Applied = true
+	}
+	return result, nil
+}
+
+// replayStreak derives current/longest streak, totals, freezes used and the completed streaks
+// from snap timestamps and frozen days, using the same rules as updateStreak and settleStreak:
+// snapped days extend a run, frozen days keep it alive without extending it, and any other past
+// day ends it. A trailing gap that the available freezes can still cover is left pending, as
+// settleStreak would. Returned epochs are oldest first.
+func replayStreak(streak *models.SnapStreak, snapDates []time.Time, frozenDates []string, loc *time.Location, now time.Time) []models.StreakEpoch {
+	streak.TotalSnaps = len(snapDates)
+	streak.FreezesUsed = len(frozenDates)
+	streak.CurrentStreak = 0
+	streak.LongestStreak = 0
+	streak.StartDate = ""
+
+	if len(snapDates) == 0 {
+		streak.LastSnapDate = time.Time{}
+		return nil
+	}
+	streak.LastSnapDate = snapDates[len(snapDates)-1]
+
+	snapped := make(map[string]bool, len(snapDates))
+	for _, t := range snapDates {
+		snapped[t.In(loc).Format("2006-01-02")] = true
+	}
+	frozen := make(map[string]bool, len(frozenDates))
+	for _, d := range frozenDates {
+		frozen[d] = true
+	}
+
+	var epochs []models.StreakEpoch
+	today := startOfDay(now, loc)
+	first := startOfDay(snapDates[0], loc)
+	lastCovered := first
+	var runStart time.Time
+	run := 0
+
+	endRun := func() {
+		if run == 0 {
+			return
+		}
+		epochs = append(epochs, models.StreakEpoch{
+			ID:        uuid.New(),
+			UserID:    streak.UserID,
+			StartDate: runStart.Format("2006-01-02"),
+			EndDate:   lastCovered.Format("2006-01-02"),
+			Length:    run,
+		})
+		run = 0
+	}
+
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		switch {
+		case snapped[key]:
+			if daysBetween(lastCovered, day) > 1 {
+				endRun()
+			}
+			if run == 0 {
+				runStart = day
+			}
+			run++
+			lastCovered = day
+			if run > streak.LongestStreak {
+				streak.LongestStreak = run
+			}
+		case frozen[key]:
+			lastCovered = day
+		}
+	}
+
+	// Days missed after the last covered day are pending until freezes run out
+	if missed := daysBetween(lastCovered, today) - 1; missed > 0 && missed > streak.FreezesAvailable {
+		endRun()
+	}
+
+	streak.CurrentStreak = run
+	if run > 0 {
+		streak.StartDate = runStart.Format("2006-01-02")
+	}
+	return epochs
+}
+
+func toStreakSummary(streak *models.SnapStreak, epochs []models.StreakEpoch) dto.StreakSummary {
+	summary := dto.StreakSummary{
+		CurrentStreak: streak.CurrentStreak,
+		LongestStreak: streak.LongestStreak,
+		TotalSnaps:    streak.TotalSnaps,
+		FreezesUsed:   streak.FreezesUsed,
+		PastStreaks:   len(epochs),
+	}
+	for _, e := range epochs {
+		summary.PastStreakDays += e.Length
+	}
+	if !streak.LastSnapDate.IsZero() {
+		summary.LastSnapDate = streak.LastSnapDate.UTC().Format(time.RFC3339)
+	}
+	return summary
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reconcileLookback is how long after expiring a subscription is still reconciled,
+// to catch late renewals, refunds and recoveries whose webhooks were missed.
+const reconcileLookback = 30 * 24 * time.Hour
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ReconcileSubscriptions is the periodic job: it compares every live or recently expired
+// subscription against RevenueCat and fixes drift. It is a no-op without an API key.
+func (s *SubscriptionService) ReconcileSubscriptions(ctx context.Context, now time.Time) error {
+	if !s.revenueCat.Enabled() {
+		return nil
+	}
+
+	report, err := s.reconcile(ctx, s.db.WithContext(ctx).
+		Where("status <> ? OR current_period_end > ?", models.SubscriptionExpired, now.Add(-reconcileLookback)))
+	if err != nil {
+		return err
+	}
+	if report.Corrected > 0 || report.Failed > 0 {
+		log.Printf("Reconciled subscriptions: %d checked, %d corrected, %d failed", report.Checked, report.Corrected, report.Failed)
+	}
+	return nil
+}
+
+// ReconcileAll reconciles every subscription on demand.
+func (s *SubscriptionService) ReconcileAll(ctx context.Context) (*dto.ReconcileReport, error) {
+	if !s.revenueCat.Enabled() {
+		return nil, ErrRevenueCatNotConfigured
+	}
+	return s.reconcile(ctx, s.db.WithContext(ctx))
+}
+
+// ReconcileSubscription reconciles one subscription on demand.
+func (s *SubscriptionService) ReconcileSubscription(ctx context.Context, id uuid.UUID) (*dto.ReconcileReport, error) {
+	if !s.revenueCat.Enabled() {
+		return nil, ErrRevenueCatNotConfigured
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Subscription{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrSubscriptionNotFound
+	}
+	return s.reconcile(ctx, s.db.WithContext(ctx).Where("id = ?", id))
+}
+
+func (s *SubscriptionService) reconcile(ctx context.Context, query *gorm.DB) (*dto.ReconcileReport, error) {
+	var subs []models.Subscription
+	if err := query.Order("created_at").Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+
+	report := &dto.ReconcileReport{Corrections: []dto.CorrectionResponse{}}
+	for i := range subs {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		report.Checked++
+
+		corrections, err := s.reconcileOne(ctx, &subs[i])
+		if err != nil {
+			log.Printf("warning: failed to reconcile subscription %s (%s): %v", subs[i].ID, subs[i].RevenueCatID, err)
+			report.Failed++
+			continue
+		}
+		if len(corrections) > 0 {
+			report.Corrected++
+			for _, c := range corrections {
+				report.Corrections = append(report.Corrections, dto.CorrectionResponse{
+					SubscriptionID: c.SubscriptionID.String(),
+					RevenueCatID:   c.RevenueCatID,
+					Field:          c.Field,
+					OldValue:       c.OldValue,
+					NewValue:       c.NewValue,
+				})
+			}
+		}
+	}
+	return report, nil
+}
+
+// reconcileOne brings one subscription in line with RevenueCat and logs each corrected field.
+// A subscription changed by a webhook newer than the RevenueCat snapshot is left alone, so a
+// reconcile that overlaps with webhook delivery can't undo it.
+func (s *SubscriptionService) reconcileOne(ctx context.Context, sub *models.Subscription) ([]models.SubscriptionCorrection, error) {
+	fetchedAt := time.Now()
+	subscriber, err := s.revenueCat.GetSubscriber(ctx, sub.RevenueCatID)
+	if errors.Is(err, ErrRevenueCatNotFound) {
+		return nil, nil // e.g. a sandbox purchase from another project
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	productID, state, ok := subscriberState(subscriber, sub.ProductID)
+	if !ok {
+		return nil, nil // nothing to compare against; leave our record alone
+	}
+
+	var current models.Subscription
+	var corrections []models.SubscriptionCorrection
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Compare against the row as it is now, locked against concurrent webhooks
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", sub.ID).Error; err != nil {
+			return err
+		}
+		if current.LastEventAtMs > fetchedAt.UnixMilli() {
+			return nil
+		}
+
+		var updates map[string]interface{}
+		updates, corrections = subscriptionCorrections(&current, subscriber, productID, state, time.Now())
+		if len(corrections) == 0 {
+			return nil
+		}
+		if err := tx.Model(&current).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(&corrections).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply corrections: %w", err)
+	}
+	if len(corrections) == 0 {
+		return nil, nil
+	}
+
+	for _, c := range corrections {
+		log.Printf("Corrected subscription %s (%s): %s %q -> %q", current.ID, current.RevenueCatID, c.Field, c.OldValue, c.NewValue)
+	}
+	if s.entitlements != nil && current.UserID != nil {
+		s.entitlements.Invalidate(*current.UserID)
+	}
+	return corrections, nil
+}
+
+// subscriptionCorrections compares a subscription with RevenueCat's state for productID at now.
+// It returns the column updates that bring it in line and a correction record per changed field.
+func subscriptionCorrections(sub *models.Subscription, subscriber *RevenueCatSubscriber, productID string, state RevenueCatSubscriptionState, now time.Time) (map[string]interface{}, []models.SubscriptionCorrection) {
+	want := *sub
+	want.ProductID = productID
+	want.Status, want.GracePeriodExpiresAt = expectedStatus(state, now)
+	want.CurrentPeriodEnd = state.ExpiresDate // nil for lifetime purchases
+	if state.PurchaseDate != nil {
+		want.CurrentPeriodStart = *state.PurchaseDate
+	}
+	want.AutoResumeAt = state.AutoResumeDate
+	var entitlements []string
+	for id, e := range subscriber.Entitlements {
+		if e.ProductIdentifier == productID {
+			entitlements = append(entitlements, id)
+		}
+	}
+	if len(entitlements) > 0 {
+		sort.Strings(entitlements)
+		want.EntitlementIDs = joinEntitlements(entitlements)
+	}
+
+	updates := map[string]interface{}{}
+	var corrections []models.SubscriptionCorrection
+	diff := func(field, column, before, after string, value interface{}) {
+		if before == after {
+			return
+		}
+		updates[column] = value
+		corrections = append(corrections, models.SubscriptionCorrection{
+			ID:             uuid.New(),
+			SubscriptionID: sub.ID,
+			UserID:         sub.UserID,
+			RevenueCatID:   sub.RevenueCatID,
+			Field:          field,
+			OldValue:       before,
+			NewValue:       after,
+		})
+	}
+	diff("product_id", "product_id", sub.ProductID, want.ProductID, want.ProductID)
+	diff("status", "status", sub.Status, want.Status, want.Status)
+	diff("current_period_start", "current_period_start", formatTime(&sub.CurrentPeriodStart), formatTime(&want.CurrentPeriodStart), want.CurrentPeriodStart)
+	diff("current_period_end", "current_period_end", formatTime(sub.CurrentPeriodEnd), formatTime(want.CurrentPeriodEnd), want.CurrentPeriodEnd)
+	diff("grace_period_expires_at", "grace_period_expires_at", formatTime(sub.GracePeriodExpiresAt), formatTime(want.GracePeriodExpiresAt), want.GracePeriodExpiresAt)
+	diff("auto_resume_at", "auto_resume_at", formatTime(sub.AutoResumeAt), formatTime(want.AutoResumeAt), want.AutoResumeAt)
+	diff("entitlement_ids", "entitlement_ids", sub.EntitlementIDs, want.EntitlementIDs, want.EntitlementIDs)
+	return updates, corrections
+}
+
+// subscriberState picks the RevenueCat subscription matching our product. If the product is
+// gone (e.g. after a product change) the latest-expiring subscription is used instead.
+func subscriberState(subscriber *RevenueCatSubscriber, productID string) (string, RevenueCatSubscriptionState, bool) {
+	if state, ok := subscriber.Subscriptions[productID]; ok {
+		return productID, state, true
+	}
+
+	var (
+		bestID    string
+		best      RevenueCatSubscriptionState
+		bestUntil time.Time
+	)
+	for id, state := range subscriber.Subscriptions {
+		if state.ExpiresDate != nil && state.ExpiresDate.After(bestUntil) {
+			bestID, best, bestUntil = id, state, *state.ExpiresDate
+		}
+	}
+	return bestID, best, bestID != ""
+}
+
+// expectedStatus derives our status from RevenueCat's subscription state at now.
+func expectedStatus(state RevenueCatSubscriptionState, now time.Time) (string, *time.Time) {
+	if state.RefundedAt != nil {
+		return models.SubscriptionExpired, nil
+	}
+
+	inGrace := state.GracePeriodExpiresDate != nil && state.GracePeriodExpiresDate.After(now)
+	if state.BillingIssuesDetectedAt != nil && inGrace {
+		return models.SubscriptionGracePeriod, state.GracePeriodExpiresDate
+	}
+	if state.ExpiresDate == nil || state.ExpiresDate.After(now) {
+		if state.UnsubscribeDetectedAt != nil {
+			return models.SubscriptionCancelled, nil
+		}
+		return models.SubscriptionActive, nil
+	}
+	if state.AutoResumeDate != nil && state.AutoResumeDate.After(now) {
+		return models.SubscriptionPaused, nil
+	}
+	return models.SubscriptionExpired, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Truncate(time.Second).Format(time.RFC3339)
+}
+
+// ListCorrections returns logged reconciliation corrections, newest first.
+func (s *SubscriptionService) ListCorrections(subscriptionID string, limit, offset int) ([]models.SubscriptionCorrection, int64, error) {
+	var corrections []models.SubscriptionCorrection
+	var total int64
+
+	query := s.db.Model(&models.SubscriptionCorrection{})
+	if subscriptionID = strings.TrimSpace(subscriptionID); subscriptionID != "" {
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+
+	query.Count(&total)
+
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&corrections).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return corrections, total, nil
+}
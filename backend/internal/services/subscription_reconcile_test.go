@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// stubRevenueCat serves GET /v1/subscribers/{app_user_id} from subscribers and 404s for anyone else.
+func stubRevenueCat(t *testing.T, subscribers map[string]RevenueCatSubscriber) *RevenueCatClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		subscriber, ok := subscribers[strings.TrimPrefix(r.URL.Path, "/v1/subscribers/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"subscriber": subscriber})
+	}))
+	t.Cleanup(server.Close)
+	return NewRevenueCatClient(server.URL, "sk_test")
+}
+
+func TestRevenueCatClientGetSubscriber(t *testing.T) {
+	client := stubRevenueCat(t, map[string]RevenueCatSubscriber{
+		"$RCAnonymousID:abc": {OriginalAppUserID: "$RCAnonymousID:abc"},
+	})
+
+	subscriber, err := client.GetSubscriber(context.Background(), "$RCAnonymousID:abc")
+	if err != nil {
+		t.Fatalf("GetSubscriber: %v", err)
+	}
+	if subscriber.OriginalAppUserID != "$RCAnonymousID:abc" {
+		t.Errorf("original app user ID = %q", subscriber.OriginalAppUserID)
+	}
+	if _, err := client.GetSubscriber(context.Background(), "missing"); !errors.Is(err, ErrRevenueCatNotFound) {
+		t.Errorf("missing subscriber: err = %v, want ErrRevenueCatNotFound", err)
+	}
+	if _, err := NewRevenueCatClient("http://unused", "").GetSubscriber(context.Background(), "x"); !errors.Is(err, ErrRevenueCatNotConfigured) {
+		t.Errorf("no API key: err = %v, want ErrRevenueCatNotConfigured", err)
+	}
+}
+
+func TestExpectedStatus(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		state RevenueCatSubscriptionState
+		want  string
+	}{
+		{"active", RevenueCatSubscriptionState{ExpiresDate: &future}, models.SubscriptionActive},
+		{"lifetime", RevenueCatSubscriptionState{}, models.SubscriptionActive},
+		{"cancelled until period end", RevenueCatSubscriptionState{ExpiresDate: &future, UnsubscribeDetectedAt: &past}, models.SubscriptionCancelled},
+		{"grace period", RevenueCatSubscriptionState{ExpiresDate: &past, BillingIssuesDetectedAt: &past, GracePeriodExpiresDate: &future}, models.SubscriptionGracePeriod},
+		{"grace period over", RevenueCatSubscriptionState{ExpiresDate: &past, BillingIssuesDetectedAt: &past, GracePeriodExpiresDate: &past}, models.SubscriptionExpired},
+		{"paused", RevenueCatSubscriptionState{ExpiresDate: &past, AutoResumeDate: &future}, models.SubscriptionPaused},
+		{"expired", RevenueCatSubscriptionState{ExpiresDate: &past}, models.SubscriptionExpired},
+		{"refunded", RevenueCatSubscriptionState{ExpiresDate: &future, RefundedAt: &past}, models.SubscriptionExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := expectedStatus(tt.state, now); got != tt.want {
+				t.Errorf("expectedStatus = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileSubscription(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	start, end, expired := now.AddDate(0, 0, -20), now.AddDate(0, 0, 10), now.AddDate(0, 0, -1)
+
+	subscriber := func(expires time.Time) RevenueCatSubscriber {
+		return RevenueCatSubscriber{
+			OriginalAppUserID: "rc-customer",
+			Entitlements: map[string]RevenueCatEntitlement{
+				"premium": {ProductIdentifier: "premium_monthly", ExpiresDate: &expires, PurchaseDate: &start},
+			},
+			Subscriptions: map[string]RevenueCatSubscriptionState{
+				"premium_monthly": {PurchaseDate: &start, ExpiresDate: &expires, PeriodType: "normal", Store: "app_store"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		subscribers   map[string]RevenueCatSubscriber
+		lastEventAtMs int64
+		wantStatus    string
+		wantPeriodEnd time.Time // zero: never expires
+		wantFields    []string  // corrected fields, in order
+	}{
+		{
+			name:          "in sync",
+			subscribers:   map[string]RevenueCatSubscriber{"rc-customer": subscriber(end)},
+			wantStatus:    models.SubscriptionActive,
+			wantPeriodEnd: end,
+		},
+		{
+			name:          "missed expiration is corrected",
+			subscribers:   map[string]RevenueCatSubscriber{"rc-customer": subscriber(expired)},
+			lastEventAtMs: now.Add(-time.Hour).UnixMilli(),
+			wantStatus:    models.SubscriptionExpired,
+			wantPeriodEnd: expired,
+			wantFields:    []string{"status", "current_period_end"},
+		},
+		{
+			name: "lifetime purchase loses its period end",
+			subscribers: map[string]RevenueCatSubscriber{"rc-customer": {
+				OriginalAppUserID: "rc-customer",
+				Subscriptions: map[string]RevenueCatSubscriptionState{
+					"premium_monthly": {PurchaseDate: &start, PeriodType: "normal", Store: "app_store"},
+				},
+			}},
+			wantStatus: models.SubscriptionActive,
+			wantFields: []string{"current_period_end"},
+		},
+		{
+			name:          "webhook newer than the snapshot wins",
+			subscribers:   map[string]RevenueCatSubscriber{"rc-customer": subscriber(expired)},
+			lastEventAtMs: now.Add(time.Hour).UnixMilli(),
+			wantStatus:    models.SubscriptionActive,
+			wantPeriodEnd: end,
+		},
+		{
+			name:          "unknown subscriber is left alone",
+			subscribers:   map[string]RevenueCatSubscriber{},
+			wantStatus:    models.SubscriptionActive,
+			wantPeriodEnd: end,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			sub := models.Subscription{
+				ID:                 uuid.New(),
+				RevenueCatID:       "rc-customer",
+				ProductID:          "premium_monthly",
+				EntitlementIDs:     "premium",
+				Status:             models.SubscriptionActive,
+				CurrentPeriodStart: start,
+				CurrentPeriodEnd:   &end,
+				LastEventAtMs:      tt.lastEventAtMs,
+			}
+			if err := db.Create(&sub).Error; err != nil {
+				t.Fatalf("create subscription: %v", err)
+			}
+			subs := NewSubscriptionService(db, nil, nil, stubRevenueCat(t, tt.subscribers))
+
+			report, err := subs.ReconcileSubscription(context.Background(), sub.ID)
+			if err != nil {
+				t.Fatalf("ReconcileSubscription: %v", err)
+			}
+			if report.Checked != 1 || report.Failed != 0 {
+				t.Errorf("report = %+v, want 1 checked and none failed", report)
+			}
+
+			var fields []string
+			for _, c := range report.Corrections {
+				fields = append(fields, c.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("corrected %v, want %v", fields, tt.wantFields)
+			}
+
+			var logged int64
+			db.Model(&models.SubscriptionCorrection{}).Where("subscription_id = ?", sub.ID).Count(&logged)
+			if int(logged) != len(tt.wantFields) {
+				t.Errorf("logged %d corrections, want %d", logged, len(tt.wantFields))
+			}
+
+			var stored models.Subscription
+			if err := db.First(&stored, "id = ?", sub.ID).Error; err != nil {
+				t.Fatalf("load subscription: %v", err)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", stored.Status, tt.wantStatus)
+			}
+			if got, want := formatTime(stored.CurrentPeriodEnd), formatTime(&tt.wantPeriodEnd); got != want {
+				t.Errorf("period end = %q, want %q", got, want)
+			}
+		})
+	}
+}
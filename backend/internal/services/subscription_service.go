@@ -1,7 +1,9 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
@@ -11,14 +13,23 @@ import (
 )
 
 type SubscriptionService struct {
-	db *gorm.DB
+	db           *gorm.DB
+	freezes      *FreezeService
+	entitlements *EntitlementService
+	revenueCat   *RevenueCatClient
 }
 
-func NewSubscriptionService(db *gorm.DB) *SubscriptionService {
-	return &SubscriptionService{db: db}
+func NewSubscriptionService(db *gorm.DB, freezes *FreezeService, entitlements *EntitlementService, revenueCat *RevenueCatClient) *SubscriptionService {
+	return &SubscriptionService{db: db, freezes: freezes, entitlements: entitlements, revenueCat: revenueCat}
 }
 
 func (s *SubscriptionService) HandleWebhookEvent(event *dto.RevenueCatEvent) error {
+	if event.Type != "TEST" {
+		if err := s.linkIdentities(event); err != nil {
+			return fmt.Errorf("failed to link app user IDs: %w", err)
+		}
+	}
+
 	switch event.Type {
 	case "INITIAL_PURCHASE":
 		return s.handleInitialPurchase(event)
@@ -26,56 +37,215 @@ func (s *SubscriptionService) HandleWebhookEvent(event *dto.RevenueCatEvent) err
 		return s.handleRenewal(event)
 	case "CANCELLATION":
 		return s.handleCancellation(event)
+	case "UNCANCELLATION":
+		return s.handleUncancellation(event)
 	case "EXPIRATION":
 		return s.handleExpiration(event)
+	case "PRODUCT_CHANGE":
+		return s.handleProductChange(event)
+	case "BILLING_ISSUE":
+		return s.handleBillingIssue(event)
+	case "SUBSCRIPTION_PAUSED":
+		return s.handleSubscriptionPaused(event)
+	case "TRANSFER":
+		return s.handleTransfer(event)
+	case "SUBSCRIBER_ALIAS":
+		return s.handleSubscriberAlias(event)
+	case "NON_RENEWING_PURCHASE":
+		return s.handleNonRenewingPurchase(event)
+	case "TEST":
+		// Sent from the RevenueCat dashboard to check the integration
+		log.Printf("Received RevenueCat test event %s", event.ID)
+		return nil
 	default:
 		// Log unknown event type but don't fail
+		log.Printf("Ignoring RevenueCat event %s of unknown type %s", event.ID, event.Type)
 		return nil
 	}
 }
 
-func (s *SubscriptionService) handleInitialPurchase(event *dto.RevenueCatEvent) error {
-	sub := models.Subscription{
-		ID:                 uuid.New(),
-		RevenueCatID:       event.AppUserID,
-		ProductID:          event.ProductID,
-		Status:             "active",
-		CurrentPeriodStart: msToTime(event.PurchasedAtMs),
-		CurrentPeriodEnd:   msToTime(event.ExpirationAtMs),
+// findSubscription returns the latest subscription recorded under any of the event's
+// app user IDs, or nil if there is none.
+func (s *SubscriptionService) findSubscription(event *dto.RevenueCatEvent) (*models.Subscription, error) {
+	var sub models.Subscription
+	err := s.db.Where("revenuecat_id IN ?", eventAppUserIDs(event)).Order("created_at DESC").First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return &sub, nil
+}
 
-	// Try to link to existing user by RevenueCat app_user_id
-	var user models.User
-	if err := s.db.Where("id = ?", event.AppUserID).First(&user).Error; err == nil {
-		sub.UserID = user.ID
+// activate creates or refreshes the subscription for a new or renewed billing period.
+func (s *SubscriptionService) activate(event *dto.RevenueCatEvent) error {
+	sub, err := s.findSubscription(event)
+	if err != nil {
+		return err
 	}
 
-	return s.db.Create(&sub).Error
-}
+	if sub == nil {
+		sub = &models.Subscription{
+			ID:           uuid.New(),
+			RevenueCatID: event.AppUserID,
+		}
+		// Link to the user behind any of the event's app user IDs
+		if userID, ok := s.resolveUser(eventAppUserIDs(event)...); ok {
+			sub.UserID = &userID
+		}
+	}
 
-func (s *SubscriptionService) handleRenewal(event *dto.RevenueCatEvent) error {
-	var sub models.Subscription
-	if err := s.db.Where("revenuecat_id = ?", event.AppUserID).First(&sub).Error; err != nil {
-		return fmt.Errorf("subscription not found for renewal: %w", err)
+	sub.ProductID = event.ProductID
+	if len(event.EntitlementIDs) > 0 {
+		sub.EntitlementIDs = joinEntitlements(event.EntitlementIDs)
+	}
+	sub.PendingProductID = ""
+	sub.Status = models.SubscriptionActive
+	sub.CurrentPeriodStart = msToTime(event.PurchasedAtMs)
+	sub.CurrentPeriodEnd = nil // lifetime purchases carry no expiration
+	if event.ExpirationAtMs > 0 {
+		end := msToTime(event.ExpirationAtMs)
+		sub.CurrentPeriodEnd = &end
 	}
+	sub.GracePeriodExpiresAt = nil
+	sub.AutoResumeAt = nil
+	return s.db.Save(sub).Error
+}
 
-	return s.db.Model(&sub).Updates(map[string]interface{}{
-		"status":               "active",
-		"current_period_end":   msToTime(event.ExpirationAtMs),
-		"current_period_start": msToTime(event.PurchasedAtMs),
-	}).Error
+func (s *SubscriptionService) handleInitialPurchase(event *dto.RevenueCatEvent) error {
+	return s.activate(event)
 }
 
+// handleRenewal starts the next period. It also covers billing recovery after a grace period
+// and resumption after a pause, and creates the subscription if the purchase was never seen.
+func (s *SubscriptionService) handleRenewal(event *dto.RevenueCatEvent) error {
+	return s.activate(event)
+}
+
+// handleCancellation turns off auto-renew; the user stays entitled until the period ends.
+// Refunds (cancel_reason CUSTOMER_SUPPORT) carry the refund time as the expiration.
 func (s *SubscriptionService) handleCancellation(event *dto.RevenueCatEvent) error {
+	updates := map[string]interface{}{"status": models.SubscriptionCancelled}
+	if event.ExpirationAtMs > 0 {
+		updates["current_period_end"] = msToTime(event.ExpirationAtMs)
+	}
 	return s.db.Model(&models.Subscription{}).
-		Where("revenuecat_id = ?", event.AppUserID).
-		Update("status", "cancelled").Error
+		Where("revenuecat_id IN ? AND status <> ?", eventAppUserIDs(event), models.SubscriptionExpired).
+		Updates(updates).Error
 }
 
+// handleUncancellation re-enables auto-renew on a cancelled subscription.
+func (s *SubscriptionService) handleUncancellation(event *dto.RevenueCatEvent) error {
+	return s.db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ? AND status = ?", eventAppUserIDs(event), models.SubscriptionCancelled).
+		Update("status", models.SubscriptionActive).Error
+}
+
+// handleExpiration ends the entitlement. A subscription expiring because of a scheduled
+// pause becomes paused rather than expired, so it can resume on its own.
 func (s *SubscriptionService) handleExpiration(event *dto.RevenueCatEvent) error {
+	sub, err := s.findSubscription(event)
+	if err != nil || sub == nil {
+		return err
+	}
+
+	status := models.SubscriptionExpired
+	if sub.AutoResumeAt != nil && sub.AutoResumeAt.After(time.Now()) {
+		status = models.SubscriptionPaused
+	}
+	updates := map[string]interface{}{"status": status, "grace_period_expires_at": nil}
+	if event.ExpirationAtMs > 0 {
+		updates["current_period_end"] = msToTime(event.ExpirationAtMs)
+	}
+	return s.db.Model(sub).Updates(updates).Error
+}
+
+// handleProductChange records an upgrade/downgrade. The new product usually takes effect at the
+// next renewal, which arrives as a RENEWAL (or INITIAL_PURCHASE) event for the new product.
+func (s *SubscriptionService) handleProductChange(event *dto.RevenueCatEvent) error {
+	if event.NewProductID == "" {
+		return nil
+	}
 	return s.db.Model(&models.Subscription{}).
-		Where("revenuecat_id = ?", event.AppUserID).
-		Update("status", "expired").Error
+		Where("revenuecat_id IN ?", eventAppUserIDs(event)).
+		Update("pending_product_id", event.NewProductID).Error
+}
+
+// handleBillingIssue moves the subscription into its grace period while the store retries
+// the charge. Without a grace period the entitlement simply ends with the current period.
+func (s *SubscriptionService) handleBillingIssue(event *dto.RevenueCatEvent) error {
+	updates := map[string]interface{}{"status": models.SubscriptionGracePeriod}
+	if event.GracePeriodExpiresAtMs != nil {
+		updates["grace_period_expires_at"] = msToTime(*event.GracePeriodExpiresAtMs)
+	}
+	return s.db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ? AND status <> ?", eventAppUserIDs(event), models.SubscriptionExpired).
+		Updates(updates).Error
+}
+
+// handleSubscriptionPaused schedules a Play Store pause. The subscription stays active until
+// the period ends; the EXPIRATION that follows marks it paused.
+func (s *SubscriptionService) handleSubscriptionPaused(event *dto.RevenueCatEvent) error {
+	if event.AutoResumeAtMs == nil {
+		return nil
+	}
+	return s.db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ?", eventAppUserIDs(event)).
+		Update("auto_resume_at", msToTime(*event.AutoResumeAtMs)).Error
+}
+
+// handleTransfer moves subscriptions from the transferred_from app user IDs to the
+// transferred_to one (e.g. a restore on another account). If the new owner isn't known yet the
+// subscription is orphaned, so the previous account loses the entitlement either way.
+func (s *SubscriptionService) handleTransfer(event *dto.RevenueCatEvent) error {
+	if len(event.TransferredFrom) == 0 || len(event.TransferredTo) == 0 {
+		return nil
+	}
+
+	to := event.TransferredTo[0]
+	updates := map[string]interface{}{"revenuecat_id": to, "user_id": nil}
+	if userID, ok := s.resolveUser(to); ok {
+		updates["user_id"] = userID
+	}
+	return s.db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ?", event.TransferredFrom).
+		Updates(updates).Error
+}
+
+// handleSubscriberAlias needs no work of its own: linkIdentities has already recorded the
+// aliases and attached their subscriptions to the user.
+func (s *SubscriptionService) handleSubscriberAlias(event *dto.RevenueCatEvent) error {
+	return nil
+}
+
+// handleNonRenewingPurchase credits consumable purchases such as streak freezes. A purchase whose
+// buyer isn't known yet is kept in the transactions table and credited once one of its app user
+// IDs is linked to a user (see creditOrphanedPurchases). Products the app doesn't sell as
+// freezes are reported as ignored so they show up in the event log.
+func (s *SubscriptionService) handleNonRenewingPurchase(event *dto.RevenueCatEvent) error {
+	if s.freezes.FreezesForProduct(event.ProductID) == 0 {
+		return fmt.Errorf("%w: product %q is not a freeze product", errEventIgnored, event.ProductID)
+	}
+
+	userID, ok := s.resolveUser(eventAppUserIDs(event)...)
+	if !ok {
+		log.Printf("RevenueCat purchase %s of %s has no known user yet; it will be credited once %q is linked",
+			event.ID, event.ProductID, event.AppUserID)
+		return nil
+	}
+
+	_, err := s.freezes.creditPurchase(s.db, userID, event.ProductID, purchaseReference(event.TransactionID, event.ID))
+	return err
+}
+
+// purchaseReference identifies a consumable purchase for idempotent crediting: the store
+// transaction ID, or the event ID when RevenueCat sends none.
+func purchaseReference(transactionID, eventID string) string {
+	if transactionID != "" {
+		return transactionID
+	}
+	return eventID
 }
 
 func msToTime(ms int64) time.Time {
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// transactionEvents carry a billing transaction worth recording for revenue analytics.
+// CANCELLATION is recorded only for refunds, which RevenueCat sends with a negative price.
+var transactionEvents = map[string]bool{
+	"INITIAL_PURCHASE":      true,
+	"RENEWAL":               true,
+	"NON_RENEWING_PURCHASE": true,
+	"CANCELLATION":          true,
+}
+
+// recordTransaction stores the price, currency, country and trial data of a billing event.
+// Redeliveries and replays of the same event are ignored; it reports whether a row was added.
+func (s *SubscriptionService) recordTransaction(event *dto.RevenueCatEvent) (bool, error) {
+	if !transactionEvents[event.Type] || (event.Type == "CANCELLATION" && event.Price >= 0) {
+		return false, nil
+	}
+
+	var occurredAt time.Time
+	switch {
+	case event.EventTimestampMs > 0:
+		occurredAt = msToTime(event.EventTimestampMs)
+	case event.PurchasedAtMs > 0:
+		occurredAt = msToTime(event.PurchasedAtMs)
+	default:
+		occurredAt = time.Now()
+	}
+
+	tx := models.SubscriptionTransaction{
+		ID:                       uuid.New(),
+		EventID:                  event.ID,
+		EventType:                event.Type,
+		RevenueCatID:             event.AppUserID,
+		OriginalTransactionID:    event.OriginalTransactionID,
+		TransactionID:            event.TransactionID,
+		ProductID:                event.ProductID,
+		Store:                    event.Store,
+		Environment:              event.Environment,
+		PeriodType:               event.PeriodType,
+		IsTrialConversion:        event.IsTrialConversion,
+		CountryCode:              event.CountryCode,
+		Currency:                 event.Currency,
+		Price:                    event.Price,
+		PriceInPurchasedCurrency: event.PriceInPurchasedCurrency,
+		PurchasedAt:              msToTime(event.PurchasedAtMs),
+		OccurredAt:               occurredAt,
+	}
+	if tx.OriginalTransactionID == "" {
+		tx.OriginalTransactionID = event.TransactionID
+	}
+	if tx.OriginalTransactionID == "" {
+		tx.OriginalTransactionID = event.AppUserID
+	}
+	if event.PurchasedAtMs == 0 {
+		tx.PurchasedAt = occurredAt
+	}
+	if event.ExpirationAtMs > 0 {
+		expiresAt := msToTime(event.ExpirationAtMs)
+		tx.ExpiresAt = &expiresAt
+	}
+	if userID, ok := s.resolveUser(eventAppUserIDs(event)...); ok {
+		tx.UserID = &userID
+	}
+
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tx)
+	return result.RowsAffected > 0, result.Error
+}
+
+// BackfillTransactions records transactions for webhook events received before they were
+// stored, using the payloads in the webhook event log. It returns how many were added.
+func (s *SubscriptionService) BackfillTransactions() (int, error) {
+	var events []models.WebhookEvent
+	err := s.db.
+		Where("type IN ? AND status IN ?", []string{"INITIAL_PURCHASE", "RENEWAL", "NON_RENEWING_PURCHASE", "CANCELLATION"},
+			[]string{models.WebhookEventProcessed, models.WebhookEventIgnored}).
+		Where("event_id NOT IN (?)", s.db.Model(&models.SubscriptionTransaction{}).Select("event_id")).
+		Order("created_at").Find(&events).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load webhook events: %w", err)
+	}
+
+	added := 0
+	for _, record := range events {
+		var body dto.RevenueCatWebhook
+		if err := json.Unmarshal([]byte(record.Payload), &body); err != nil {
+			log.Printf("warning: cannot parse stored webhook event %s: %v", record.EventID, err)
+			continue
+		}
+		body.Event.ID = record.EventID
+
+		recorded, err := s.recordTransaction(&body.Event)
+		if err != nil {
+			return added, err
+		}
+		if recorded {
+			added++
+		}
+	}
+	return added, nil
+}
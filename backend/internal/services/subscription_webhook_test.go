@@ -0,0 +1,292 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Users and times in the testdata/revenuecat fixtures.
+var (
+	fixtureUser      = uuid.MustParse("2f0c6a44-8d1e-4a55-9b3e-5c1f0e6d7a01")
+	fixtureOtherUser = uuid.MustParse("2f0c6a44-8d1e-4a55-9b3e-5c1f0e6d7a02")
+)
+
+const (
+	fixtureAnonymousID      = "$RCAnonymousID:8b1f2c3d4e5f40718293a4b5c6d7e8f9"
+	fixtureTransferTargetID = "$RCAnonymousID:ffe0d1c2b3a4f5e6d7c8b9a0f1e2d3c4"
+	fixtureStartMs          = int64(1767225600000) // 2026-01-01, the initial purchase
+	fixtureDayMs            = int64(24 * time.Hour / time.Millisecond)
+)
+
+func fixtureDay(days int64) time.Time {
+	return msToTime(fixtureStartMs + days*fixtureDayMs)
+}
+
+// deliverFixture sends a stored RevenueCat payload through the webhook pipeline and returns its event ID.
+func deliverFixture(t *testing.T, subs *SubscriptionService, name string) string {
+	t.Helper()
+
+	payload, err := os.ReadFile(filepath.Join("testdata", "revenuecat", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var webhook dto.RevenueCatWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		t.Fatalf("decode fixture %s: %v", name, err)
+	}
+	if err := subs.ProcessWebhook(payload, &webhook.Event); err != nil {
+		t.Fatalf("process %s: %v", name, err)
+	}
+	return webhook.Event.ID
+}
+
+type subscriptionWant struct {
+	status           string
+	productID        string
+	pendingProductID string
+	revenueCatID     string
+	userID           *uuid.UUID
+	periodEnd        time.Time // zero: never expires
+	graceEnd         *time.Time
+}
+
+func TestWebhookSubscriptionState(t *testing.T) {
+	graceEnd := fixtureDay(46)
+
+	tests := []struct {
+		name        string
+		deliveries  []string
+		want        *subscriptionWant // nil: no subscription is stored
+		eventStatus map[string]string // fixture -> webhook event status after all deliveries
+		freezes     int               // freezes credited to fixtureUser
+	}{
+		{
+			name:       "initial purchase",
+			deliveries: []string{"initial_purchase.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "lifetime purchase never expires",
+			deliveries: []string{"initial_purchase_lifetime.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_lifetime",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser,
+			},
+		},
+		{
+			name:       "renewal extends the period",
+			deliveries: []string{"initial_purchase.json", "renewal.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(60),
+			},
+		},
+		{
+			name:       "cancellation keeps the period",
+			deliveries: []string{"initial_purchase.json", "cancellation.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionCancelled, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "uncancellation reactivates",
+			deliveries: []string{"initial_purchase.json", "cancellation.json", "uncancellation.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "billing issue starts the grace period",
+			deliveries: []string{"initial_purchase.json", "billing_issue.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionGracePeriod, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(30), graceEnd: &graceEnd,
+			},
+		},
+		{
+			name:       "expiration after a billing issue",
+			deliveries: []string{"initial_purchase.json", "billing_issue.json", "expiration.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionExpired, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(46),
+			},
+		},
+		{
+			name:       "product change is pending until renewal",
+			deliveries: []string{"initial_purchase.json", "product_change.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly", pendingProductID: "premium_annual",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "transfer moves the subscription to the new owner",
+			deliveries: []string{"initial_purchase.json", "transfer.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureOtherUser.String(), userID: &fixtureOtherUser, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "transfer to an unknown owner orphans the subscription",
+			deliveries: []string{"initial_purchase.json", "transfer_unknown_destination.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureTransferTargetID, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "anonymous purchase is stored without a user",
+			deliveries: []string{"initial_purchase_anonymous.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureAnonymousID, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "subscriber alias links an anonymous purchase",
+			deliveries: []string{"initial_purchase_anonymous.json", "subscriber_alias.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureAnonymousID, userID: &fixtureUser, periodEnd: fixtureDay(30),
+			},
+		},
+		{
+			name:       "stale cancellation after a renewal is skipped",
+			deliveries: []string{"initial_purchase.json", "renewal.json", "cancellation.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(60),
+			},
+			eventStatus: map[string]string{
+				"renewal.json":      models.WebhookEventProcessed,
+				"cancellation.json": models.WebhookEventSkipped,
+			},
+		},
+		{
+			name:       "billing issue delivered after the expiration is skipped",
+			deliveries: []string{"initial_purchase.json", "expiration.json", "billing_issue.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionExpired, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(46),
+			},
+			eventStatus: map[string]string{"billing_issue.json": models.WebhookEventSkipped},
+		},
+		{
+			name:       "redelivered cancellation applies once",
+			deliveries: []string{"initial_purchase.json", "cancellation.json", "uncancellation.json", "cancellation.json"},
+			want: &subscriptionWant{
+				status: models.SubscriptionActive, productID: "premium_monthly",
+				revenueCatID: fixtureUser.String(), userID: &fixtureUser, periodEnd: fixtureDay(30),
+			},
+			eventStatus: map[string]string{"cancellation.json": models.WebhookEventProcessed},
+		},
+		{
+			name:        "freeze purchase is credited once",
+			deliveries:  []string{"non_renewing_purchase.json", "non_renewing_purchase.json"},
+			eventStatus: map[string]string{"non_renewing_purchase.json": models.WebhookEventProcessed},
+			freezes:     3,
+		},
+		{
+			name:        "anonymous freeze purchase is credited once the ID is linked",
+			deliveries:  []string{"non_renewing_purchase_anonymous.json", "subscriber_alias.json"},
+			eventStatus: map[string]string{"non_renewing_purchase_anonymous.json": models.WebhookEventProcessed},
+			freezes:     3,
+		},
+		{
+			name:        "unknown non-renewing product is logged as ignored",
+			deliveries:  []string{"non_renewing_purchase_unknown_product.json"},
+			eventStatus: map[string]string{"non_renewing_purchase_unknown_product.json": models.WebhookEventIgnored},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			createTestUser(t, db, fixtureUser)
+			createTestUser(t, db, fixtureOtherUser)
+			freezes := NewFreezeService(db, "streak_freeze_1:1,streak_freeze_3:3", nil)
+			subs := NewSubscriptionService(db, freezes, nil, nil)
+
+			eventIDs := make(map[string]string)
+			for _, name := range tt.deliveries {
+				eventIDs[name] = deliverFixture(t, subs, name)
+			}
+
+			var stored []models.Subscription
+			if err := db.Find(&stored).Error; err != nil {
+				t.Fatalf("load subscriptions: %v", err)
+			}
+			if tt.want == nil {
+				if len(stored) != 0 {
+					t.Fatalf("got %d subscriptions, want none", len(stored))
+				}
+			} else {
+				if len(stored) != 1 {
+					t.Fatalf("got %d subscriptions, want 1", len(stored))
+				}
+				assertSubscription(t, &stored[0], tt.want)
+			}
+
+			for name, want := range tt.eventStatus {
+				var event models.WebhookEvent
+				if err := db.Where("event_id = ?", eventIDs[name]).First(&event).Error; err != nil {
+					t.Fatalf("load event %s: %v", name, err)
+				}
+				if event.Status != want {
+					t.Errorf("%s: status = %q, want %q", name, event.Status, want)
+				}
+			}
+
+			var streak models.SnapStreak
+			db.Where("user_id = ?", fixtureUser).Limit(1).Find(&streak)
+			if streak.FreezesAvailable != tt.freezes {
+				t.Errorf("freezes = %d, want %d", streak.FreezesAvailable, tt.freezes)
+			}
+		})
+	}
+}
+
+func assertSubscription(t *testing.T, got *models.Subscription, want *subscriptionWant) {
+	t.Helper()
+
+	if got.Status != want.status {
+		t.Errorf("status = %q, want %q", got.Status, want.status)
+	}
+	if got.ProductID != want.productID {
+		t.Errorf("product = %q, want %q", got.ProductID, want.productID)
+	}
+	if got.PendingProductID != want.pendingProductID {
+		t.Errorf("pending product = %q, want %q", got.PendingProductID, want.pendingProductID)
+	}
+	if got.RevenueCatID != want.revenueCatID {
+		t.Errorf("revenuecat_id = %q, want %q", got.RevenueCatID, want.revenueCatID)
+	}
+	switch {
+	case want.userID == nil && got.UserID != nil:
+		t.Errorf("user = %s, want none", *got.UserID)
+	case want.userID != nil && (got.UserID == nil || *got.UserID != *want.userID):
+		t.Errorf("user = %v, want %s", got.UserID, *want.userID)
+	}
+	if formatTime(got.CurrentPeriodEnd) != formatTime(&want.periodEnd) {
+		t.Errorf("period end = %q, want %q", formatTime(got.CurrentPeriodEnd), formatTime(&want.periodEnd))
+	}
+	switch {
+	case want.graceEnd == nil && got.GracePeriodExpiresAt != nil:
+		t.Errorf("grace period end = %s, want none", *got.GracePeriodExpiresAt)
+	case want.graceEnd != nil && (got.GracePeriodExpiresAt == nil || !got.GracePeriodExpiresAt.Equal(*want.graceEnd)):
+		t.Errorf("grace period end = %v, want %s", got.GracePeriodExpiresAt, *want.graceEnd)
+	}
+}
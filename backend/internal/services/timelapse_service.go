@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	timelapseFrameSize  = 480              // square frame, pixels
+	timelapseFrameDelay = 25               // GIF delay per frame, 1/100s
+	timelapseFPS        = 4                // MP4 frame rate, matching the GIF delay
+	timelapseStaleAfter = 10 * time.Minute // processing jobs not updated for this long are retried
+
+	// The GIF encoder needs every paletted frame in memory (~225 KB each), so longer ranges are
+	// sampled down to this many evenly spaced days. MP4 frames go to disk and aren't capped.
+	timelapseMaxGIFFrames = 120
+)
+
+var (
+	ErrTimelapseNotFound   = errors.New("time-lapse not found")
+	ErrTimelapseInProgress = errors.New("a time-lapse is already being generated")
+	ErrTimelapseFormat     = errors.New("unsupported time-lapse format")
+	ErrTimelapseWebP       = errors.New("WebP time-lapses are not supported; Go has no WebP encoder, use gif or mp4")
+	ErrTimelapseNotReady   = errors.New("time-lapse is not ready")
+	errTimelapseNoFrames   = errors.New("no snaps with a supported image in this range")
+)
+
+// TimelapseService queues time-lapse jobs and renders them in the background: animated GIFs
+// in pure Go, or MP4 when an ffmpeg binary is available on the host. WebP is not offered: the
+// standard library and golang.org/x/image can only decode it.
+type TimelapseService struct {
+	db     *gorm.DB
+	dir    string
+	ffmpeg string // empty when ffmpeg isn't installed
+}
+
+// NewTimelapseService stores outputs in dir, which must not be publicly served.
+func NewTimelapseService(db *gorm.DB, dir string) *TimelapseService {
+	ffmpeg, _ := exec.LookPath("ffmpeg")
+	return &TimelapseService{db: db, dir: dir, ffmpeg: ffmpeg}
+}
+
+// Formats returns the output formats this server can produce.
+func (s *TimelapseService) Formats() []string {
+	if s.ffmpeg != "" {
+		return []string{models.TimelapseGIF, models.TimelapseMP4}
+	}
+	return []string{models.TimelapseGIF}
+}
+
+// CreateJob queues a time-lapse of the user's snaps between from and to (YYYY-MM-DD, inclusive,
+// in the user's time zone). An empty format picks MP4 when available, GIF otherwise.
+// Only one job per user may be pending at a time.
+func (s *TimelapseService) CreateJob(userID uuid.UUID, from, to, format string) (*models.TimelapseJob, error) {
+	switch format {
+	case "":
+		format = s.Formats()[len(s.Formats())-1]
+	case models.TimelapseGIF:
+	case models.TimelapseMP4:
+		if s.ffmpeg == "" {
+			return nil, ErrTimelapseFormat
+		}
+	case "webp":
+		return nil, ErrTimelapseWebP
+	default:
+		return nil, ErrTimelapseFormat
+	}
+
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, ErrInvalidDateRange
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, ErrInvalidDateRange
+	}
+	if span := daysBetween(start, end) + 1; span < 1 || span > MaxCalendarDays {
+		return nil, ErrInvalidDateRange
+	}
+
+	var pending int64
+	if err := s.db.Model(&models.TimelapseJob{}).
+		Where("user_id = ? AND status IN ?", userID, []string{models.TimelapseQueued, models.TimelapseProcessing}).
+		Count(&pending).Error; err != nil {
+		return nil, fmt.Errorf("failed to check pending time-lapses: %w", err)
+	}
+	if pending > 0 {
+		return nil, ErrTimelapseInProgress
+	}
+
+	job := models.TimelapseJob{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Format:   format,
+		FromDate: from,
+		ToDate:   to,
+		Status:   models.TimelapseQueued,
+	}
+	if err := s.db.Create(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue time-lapse: %w", err)
+	}
+	return &job, nil
+}
+
+// GetJob returns one of the user's time-lapse jobs.
+func (s *TimelapseService) GetJob(userID, jobID uuid.UUID) (*models.TimelapseJob, error) {
+	var job models.TimelapseJob
+	if err := s.db.Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		return nil, ErrTimelapseNotFound
+	}
+	return &job, nil
+}
+
+// GetOutput returns the rendered file of a finished job.
+func (s *TimelapseService) GetOutput(userID, jobID uuid.UUID) (*models.TimelapseJob, error) {
+	job, err := s.GetJob(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.TimelapseDone || job.OutputPath == "" {
+		return nil, ErrTimelapseNotReady
+	}
+	return job, nil
+}
+
+// ProcessJobs renders queued time-lapses until none are left. Each job is claimed with
+// SKIP LOCKED, so several replicas can work through the queue without doubling up;
+// jobs abandoned mid-render (e.g. by a crashed replica) are claimed again once stale.
+func (s *TimelapseService) ProcessJobs(ctx context.Context, now time.Time) error {
+	for ctx.Err() == nil {
+		var job models.TimelapseJob
+		result := s.db.WithContext(ctx).Raw(`
+			UPDATE timelapse_jobs SET status = ?, progress = 0, updated_at = ?
+			WHERE id = (
+				SELECT id FROM timelapse_jobs
+				WHERE status = ? OR (status = ? AND updated_at < ?)
+				ORDER BY created_at LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *`,
+			models.TimelapseProcessing, time.Now(),
+			models.TimelapseQueued, models.TimelapseProcessing, time.Now().Add(-timelapseStaleAfter),
+		).Scan(&job)
+		if result.Error != nil {
+			return fmt.Errorf("failed to claim time-lapse job: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := s.render(ctx, &job); err != nil {
+			if ctx.Err() != nil {
+				return nil // shutting down; the job is retried once stale
+			}
+			log.Printf("warning: time-lapse %s for user %s failed: %v", job.ID, job.UserID, err)
+			message := "Failed to generate time-lapse"
+			if errors.Is(err, errTimelapseNoFrames) {
+				message = err.Error()
+			}
+			s.db.Model(&job).Updates(map[string]interface{}{"status": models.TimelapseFailed, "error": message})
+		}
+	}
+	return nil
+}
+
+// render compiles the job's frames and marks it done.
+func (s *TimelapseService) render(ctx context.Context, job *models.TimelapseJob) error {
+	var user models.User
+	if err := s.db.Select("id", "timezone").First(&user, "id = ?", job.UserID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	loc := user.Location()
+	start, _ := time.ParseInLocation("2006-01-02", job.FromDate, loc)
+	end, _ := time.ParseInLocation("2006-01-02", job.ToDate, loc)
+
+	// One frame per day: the first snap of each local day
+	var snaps []models.Snap
+	err := s.db.Raw(`SELECT DISTINCT ON ((snap_date AT TIME ZONE ?)::date) *
+		FROM snaps WHERE user_id = ? AND deleted_at IS NULL AND snap_date >= ? AND snap_date < ?
+		ORDER BY (snap_date AT TIME ZONE ?)::date, snap_date`,
+		loc.String(), job.UserID, start, end.AddDate(0, 0, 1), loc.String()).Scan(&snaps).Error
+	if err != nil {
+		return fmt.Errorf("failed to load snaps: %w", err)
+	}
+
+	// Frames are handed to the encoder as they are decoded, so a year of snaps
+	// never has to be held in memory at full color
+	var encoder frameEncoder = &gifEncoder{}
+	if job.Format == models.TimelapseGIF {
+		snaps = sampleFrames(snaps, timelapseMaxGIFFrames)
+	}
+	if job.Format == models.TimelapseMP4 {
+		encoder, err = newMP4Encoder(s.ffmpeg)
+		if err != nil {
+			return err
+		}
+	}
+	defer encoder.Close()
+
+	frame := image.NewRGBA(image.Rect(0, 0, timelapseFrameSize, timelapseFrameSize))
+	frameCount := 0
+	for i, snap := range snaps {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		src, err := loadSnapImage(snap.ImageURL)
+		if err != nil {
+			continue // e.g. HEIC, which the standard library can't decode
+		}
+		drawThumbnail(frame, frame.Bounds(), src)
+		if err := encoder.Add(frame); err != nil {
+			return err
+		}
+		frameCount++
+
+		s.setProgress(job, (i+1)*80/len(snaps))
+	}
+	if frameCount == 0 {
+		return errTimelapseNoFrames
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	s.setProgress(job, 90)
+	output := filepath.Join(s.dir, job.ID.String()+"."+job.Format)
+	if err := encoder.Encode(ctx, output); err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	now := time.Now()
+	return s.db.Model(job).Updates(map[string]interface{}{
+		"status":       models.TimelapseDone,
+		"progress":     100,
+		"frame_count":  frameCount,
+		"output_path":  output,
+		"completed_at": now,
+	}).Error
+}
+
+// sampleFrames keeps at most max snaps, evenly spaced and always including the first and last.
+func sampleFrames(snaps []models.Snap, max int) []models.Snap {
+	if len(snaps) <= max {
+		return snaps
+	}
+	sampled := make([]models.Snap, max)
+	for i := range sampled {
+		sampled[i] = snaps[i*(len(snaps)-1)/(max-1)]
+	}
+	return sampled
+}
+
+// setProgress records progress (which also keeps the job from being considered stale).
+func (s *TimelapseService) setProgress(job *models.TimelapseJob, progress int) {
+	if progress <= job.Progress {
+		return
+	}
+	job.Progress = progress
+	s.db.Model(job).Update("progress", progress)
+}
+
+// frameEncoder accumulates time-lapse frames and writes the finished file.
+type frameEncoder interface {
+	Add(frame *image.RGBA) error
+	Encode(ctx context.Context, path string) error
+	Close()
+}
+
+// gifEncoder builds an endlessly looping animated GIF using the web-safe palette.
+type gifEncoder struct {
+	anim gif.GIF
+}
+
+func (e *gifEncoder) Add(frame *image.RGBA) error {
+	paletted := image.NewPaletted(frame.Bounds(), palette.WebSafe)
+	draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, image.Point{})
+	e.anim.Image = append(e.anim.Image, paletted)
+	e.anim.Delay = append(e.anim.Delay, timelapseFrameDelay)
+	return nil
+}
+
+func (e *gifEncoder) Encode(_ context.Context, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gif.EncodeAll(f, &e.anim)
+}
+
+func (e *gifEncoder) Close() {}
+
+// mp4Encoder writes frames as PNGs to a temporary directory and encodes them with ffmpeg.
+type mp4Encoder struct {
+	ffmpeg string
+	dir    string
+	frames int
+}
+
+func newMP4Encoder(ffmpeg string) (*mp4Encoder, error) {
+	dir, err := os.MkdirTemp("", "timelapse-")
+	if err != nil {
+		return nil, err
+	}
+	return &mp4Encoder{ffmpeg: ffmpeg, dir: dir}, nil
+}
+
+func (e *mp4Encoder) Add(frame *image.RGBA) error {
+	f, err := os.Create(filepath.Join(e.dir, fmt.Sprintf("frame_%05d.png", e.frames)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := png.Encode(f, frame); err != nil {
+		return err
+	}
+	e.frames++
+	return nil
+}
+
+func (e *mp4Encoder) Encode(ctx context.Context, path string) error {
+	cmd := exec.CommandContext(ctx, e.ffmpeg, "-y", "-loglevel", "error",
+		"-framerate", fmt.Sprint(timelapseFPS),
+		"-i", filepath.Join(e.dir, "frame_%05d.png"),
+		"-c:v", "libx264", "-pix_fmt", "yuv420p", "-movflags", "+faststart",
+		path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %v: %s", err, out)
+	}
+	return nil
+}
+
+func (e *mp4Encoder) Close() {
+	os.RemoveAll(e.dir)
+}
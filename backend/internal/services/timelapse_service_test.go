@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+)
+
+func TestSampleFrames(t *testing.T) {
+	first := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	snaps := make([]models.Snap, 365)
+	for i := range snaps {
+		snaps[i].SnapDate = first.AddDate(0, 0, i)
+	}
+
+	if got := sampleFrames(snaps[:10], 120); len(got) != 10 {
+		t.Errorf("short range: got %d frames, want all 10", len(got))
+	}
+
+	got := sampleFrames(snaps, 120)
+	if len(got) != 120 {
+		t.Fatalf("got %d frames, want 120", len(got))
+	}
+	if !got[0].SnapDate.Equal(snaps[0].SnapDate) || !got[len(got)-1].SnapDate.Equal(snaps[364].SnapDate) {
+		t.Errorf("first/last = %s/%s, want the range's first and last day", got[0].SnapDate, got[len(got)-1].SnapDate)
+	}
+	for i := 1; i < len(got); i++ {
+		if !got[i].SnapDate.After(got[i-1].SnapDate) {
+			t.Fatalf("frame %d (%s) is not after %s", i, got[i].SnapDate, got[i-1].SnapDate)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWebhookEventNotFound = errors.New("webhook event not found")
+	ErrMissingEventID       = errors.New("webhook event has no id")
+
+	// errEventIgnored is returned by event handlers for valid events the app has no use for.
+	// The event is acknowledged and logged as ignored rather than processed.
+	errEventIgnored = errors.New("event ignored")
+)
+
+// subscriptionStateEvents change a subscription's status or period. They are applied in
+// event_timestamp_ms order per app user; one older than the last applied event is skipped.
+var subscriptionStateEvents = map[string]bool{
+	"INITIAL_PURCHASE":    true,
+	"RENEWAL":             true,
+	"CANCELLATION":        true,
+	"UNCANCELLATION":      true,
+	"EXPIRATION":          true,
+	"PRODUCT_CHANGE":      true,
+	"BILLING_ISSUE":       true,
+	"SUBSCRIPTION_PAUSED": true,
+}
+
+// ProcessWebhook stores an incoming event (payload is the raw webhook body) and applies it
+// exactly once. Redeliveries of an event that was already processed or skipped are acknowledged
+// without side effects; redeliveries of a failed or ignored event retry it.
+func (s *SubscriptionService) ProcessWebhook(payload []byte, event *dto.RevenueCatEvent) error {
+	if event.ID == "" {
+		return ErrMissingEventID
+	}
+
+	record := models.WebhookEvent{
+		ID:               uuid.New(),
+		EventID:          event.ID,
+		Type:             event.Type,
+		AppUserID:        event.AppUserID,
+		EventTimestampMs: event.EventTimestampMs,
+		Payload:          string(payload),
+		Status:           models.WebhookEventReceived,
+	}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to store webhook event: %w", err)
+	}
+
+	return s.apply(event.ID, event)
+}
+
+// apply processes a stored event while holding its row lock, so concurrent deliveries of the
+// same event are serialized and the second one sees it already processed.
+func (s *SubscriptionService) apply(eventID string, event *dto.RevenueCatEvent) error {
+	var handlerErr error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var record models.WebhookEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ?", eventID).First(&record).Error; err != nil {
+			return err
+		}
+		if record.Status == models.WebhookEventProcessed || record.Status == models.WebhookEventSkipped {
+			return nil
+		}
+
+		status := models.WebhookEventProcessed
+		record.Attempts++
+		record.Error = ""
+
+		txService := &SubscriptionService{db: tx, freezes: s.freezes, entitlements: s.entitlements}
+		stale, err := txService.isStale(event)
+		if err == nil && stale {
+			status = models.WebhookEventSkipped
+		}
+		var ignored error
+		if err == nil {
+			err = tx.Transaction(func(inner *gorm.DB) error {
+				applied := &SubscriptionService{db: inner, freezes: s.freezes, entitlements: s.entitlements}
+				if !stale {
+					if err := applied.HandleWebhookEvent(event); err != nil {
+						if !errors.Is(err, errEventIgnored) {
+							return err
+						}
+						ignored = err
+					} else if err := applied.markApplied(event); err != nil {
+						return err
+					}
+				}
+				// A stale event's state change is obsolete, but its revenue still counts
+				_, err := applied.recordTransaction(event)
+				return err
+			})
+		}
+		if err != nil {
+			// The savepoint was rolled back; record the failure and let the caller report it
+			handlerErr = err
+			status = models.WebhookEventFailed
+			record.Error = err.Error()
+		} else if ignored != nil {
+			status = models.WebhookEventIgnored
+			record.Error = ignored.Error()
+			log.Printf("RevenueCat event %s (%s) ignored: %v", eventID, event.Type, ignored)
+		}
+
+		now := time.Now()
+		record.Status = status
+		if status != models.WebhookEventFailed {
+			record.ProcessedAt = &now
+		}
+		return tx.Save(&record).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to process webhook event: %w", err)
+	}
+	if handlerErr != nil {
+		log.Printf("warning: RevenueCat event %s (%s) failed: %v", eventID, event.Type, handlerErr)
+		return handlerErr
+	}
+
+	if s.entitlements != nil {
+		appUserIDs := append(eventAppUserIDs(event), event.TransferredFrom...)
+		appUserIDs = append(appUserIDs, event.TransferredTo...)
+		s.entitlements.InvalidateAppUsers(appUserIDs...)
+	}
+	return nil
+}
+
+// isStale reports whether a subscription state event is older than the latest one already applied.
+func (s *SubscriptionService) isStale(event *dto.RevenueCatEvent) (bool, error) {
+	if !subscriptionStateEvents[event.Type] || event.EventTimestampMs == 0 {
+		return false, nil
+	}
+	var newer int64
+	err := s.db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ? AND last_event_at_ms > ?", eventAppUserIDs(event), event.EventTimestampMs).
+		Count(&newer).Error
+	return newer > 0, err
+}
+
+// markApplied records the event time on the subscription it changed.
+func (s *SubscriptionService) markApplied(event *dto.RevenueCatEvent) error {
+	if !subscriptionStateEvents[event.Type] || event.EventTimestampMs == 0 {
+		return nil
+	}
+	return s.db.Model(&models.Subscription{}).
+		Where("revenuecat_id IN ? AND last_event_at_ms < ?", eventAppUserIDs(event), event.EventTimestampMs).
+		Update("last_event_at_ms", event.EventTimestampMs).Error
+}
+
+// ListWebhookEvents returns logged events, newest first, optionally filtered by status.
+func (s *SubscriptionService) ListWebhookEvents(status string, limit, offset int) ([]models.WebhookEvent, int64, error) {
+	var events []models.WebhookEvent
+	var total int64
+
+	query := s.db.Model(&models.WebhookEvent{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// ReplayWebhookEvent processes a logged event again from its stored payload and returns the log
+// entry as it was before and after. Events that were already processed or skipped are left as
+// they are.
+func (s *SubscriptionService) ReplayWebhookEvent(id uuid.UUID) (before, after *models.WebhookEvent, err error) {
+	var record models.WebhookEvent
+	if err := s.db.Where("id = ?", id).First(&record).Error; err != nil {
+		return nil, nil, ErrWebhookEventNotFound
+	}
+	previous := record
+
+	var webhook dto.RevenueCatWebhook
+	if err := json.Unmarshal([]byte(record.Payload), &webhook); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode stored payload: %w", err)
+	}
+
+	replayErr := s.apply(record.EventID, &webhook.Event)
+
+	if err := s.db.Where("id = ?", id).First(&record).Error; err != nil {
+		return nil, nil, err
+	}
+	return &previous, &record, replayErr
+}
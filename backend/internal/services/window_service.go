@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/fully-autonomous-mobile-system/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Snap window bounds, in minutes after local midnight. Windows open between 09:00 and 20:00
+// local time so they always close by 22:00.
+const (
+	SnapWindowDuration     = 2 * time.Hour
+	snapWindowEarliestOpen = 9 * 60
+	snapWindowLatestOpen   = 20 * 60
+	snapWindowStepMinutes  = 5
+)
+
+// Values for models.Snap.WindowStatus.
+const (
+	SnapEarly  = "early" // before the day's window opened
+	SnapOnTime = "on_time"
+	SnapLate   = "late" // after the day's window closed
+)
+
+// SnapWindow is the daily 2-hour window in which a snap counts as "on time".
+type SnapWindow struct {
+	Date     string // YYYY-MM-DD in the window's time zone
+	OpensAt  time.Time
+	ClosesAt time.Time
+}
+
+// Contains reports whether t falls inside the window.
+func (w SnapWindow) Contains(t time.Time) bool {
+	return !t.Before(w.OpensAt) && t.Before(w.ClosesAt)
+}
+
+// Status classifies a snap taken at t on the window's day as early, on time or late.
+func (w SnapWindow) Status(t time.Time) string {
+	switch {
+	case t.Before(w.OpensAt):
+		return SnapEarly
+	case t.Before(w.ClosesAt):
+		return SnapOnTime
+	}
+	return SnapLate
+}
+
+// SnapWindowFor returns the snap window for the local day containing t in loc. The opening
+// time is derived from a hash of the date and zone name, so every replica (and every user
+// in the same zone) agrees on it without storing anything.
+func SnapWindowFor(t time.Time, loc *time.Location) SnapWindow {
+	midnight := startOfDay(t, loc)
+	date := midnight.Format("2006-01-02")
+
+	h := fnv.New32a()
+	h.Write([]byte(date + "|" + loc.String()))
+	slots := uint32((snapWindowLatestOpen - snapWindowEarliestOpen) / snapWindowStepMinutes)
+	offset := snapWindowEarliestOpen + int(h.Sum32()%(slots+1))*snapWindowStepMinutes
+
+	opensAt := midnight.Add(time.Duration(offset) * time.Minute)
+	return SnapWindow{
+		Date:     date,
+		OpensAt:  opensAt,
+		ClosesAt: opensAt.Add(SnapWindowDuration),
+	}
+}
+
+// startOfDay returns local midnight of the day containing t in loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// WindowService exposes snap windows to clients and announces them when they open.
+type WindowService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+}
+
+func NewWindowService(db *gorm.DB, notifications *NotificationService) *WindowService {
+	return &WindowService{db: db, notifications: notifications}
+}
+
+// CurrentWindow returns today's snap window in the user's time zone.
+func (s *WindowService) CurrentWindow(userID uuid.UUID, now time.Time) (*SnapWindow, *time.Location, error) {
+	var user models.User
+	if err := s.db.Select("id", "timezone").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	loc := user.Location()
+	window := SnapWindowFor(now, loc)
+	return &window, loc, nil
+}
+
+// NotifyOpenWindows tells users that their snap window has opened. Windows are computed
+// per time zone, so only zones whose window is open right now are expanded into users.
+func (s *WindowService) NotifyOpenWindows(ctx context.Context, now time.Time) error {
+	var zones []string
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Distinct().Pluck("timezone", &zones).Error; err != nil {
+		return fmt.Errorf("failed to load time zones: %w", err)
+	}
+
+	sent := 0
+	for _, zone := range zones {
+		loc := (&models.User{Timezone: zone}).Location()
+		window := SnapWindowFor(now, loc)
+		if !window.Contains(now) {
+			continue
+		}
+
+		var userIDs []uuid.UUID
+		if err := s.db.WithContext(ctx).Model(&models.User{}).Where("timezone = ?", zone).Pluck("id", &userIDs).Error; err != nil {
+			return fmt.Errorf("failed to load users in %s: %w", zone, err)
+		}
+
+		title := "📸 Time to snap!"
+		body := fmt.Sprintf("Your snap window is open until %s. Show us what you're up to right now.", window.ClosesAt.In(loc).Format("15:04"))
+		for _, userID := range userIDs {
+			delivered, err := s.notifications.NotifyOnce(userID, models.NotificationSnapWindow, window.Date, title, body)
+			if err != nil {
+				log.Printf("warning: failed to send snap window notification to user %s: %v", userID, err)
+				continue
+			}
+			if delivered {
+				sent++
+			}
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d snap window notifications", sent)
+	}
+	return nil
+}
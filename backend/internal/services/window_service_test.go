@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapWindowStatus(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	window := SnapWindowFor(time.Date(2026, 3, 1, 12, 0, 0, 0, loc), loc)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"just after midnight", startOfDay(window.OpensAt, loc), SnapEarly},
+		{"a minute before it opens", window.OpensAt.Add(-time.Minute), SnapEarly},
+		{"as it opens", window.OpensAt, SnapOnTime},
+		{"a minute before it closes", window.ClosesAt.Add(-time.Minute), SnapOnTime},
+		{"as it closes", window.ClosesAt, SnapLate},
+		{"late evening", startOfDay(window.OpensAt, loc).Add(23 * time.Hour), SnapLate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := window.Status(tt.at); got != tt.want {
+				t.Errorf("Status(%s) = %q, want %q", tt.at.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}